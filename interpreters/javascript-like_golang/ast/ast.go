@@ -2,13 +2,16 @@ package ast
 
 import (
 	"bytes"
-	"main/types"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/types"
+	"math/big"
 	"strings"
 )
 
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() types.Position
+	End() types.Position
 }
 
 type Statement interface {
@@ -33,6 +36,20 @@ func (p *Program) TokenLiteral() string {
 	}
 }
 
+func (p *Program) Pos() types.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return types.Position{}
+}
+
+func (p *Program) End() types.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[len(p.Statements)-1].End()
+	}
+	return types.Position{}
+}
+
 func (p *Program) String() string {
 	var out bytes.Buffer
 	for _, s := range p.Statements {
@@ -49,6 +66,16 @@ type LetStatement struct {
 
 func (ls *LetStatement) statementNode()       {}
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LetStatement) Pos() types.Position  { return ls.Token.Start }
+func (ls *LetStatement) End() types.Position {
+	if ls.Value != nil {
+		return ls.Value.End()
+	}
+	if ls.Name != nil {
+		return ls.Name.End()
+	}
+	return ls.Token.End
+}
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString(ls.TokenLiteral() + " ")
@@ -68,6 +95,13 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() types.Position  { return rs.Token.Start }
+func (rs *ReturnStatement) End() types.Position {
+	if rs.ReturnValue != nil {
+		return rs.ReturnValue.End()
+	}
+	return rs.Token.End
+}
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString(rs.TokenLiteral() + " ")
@@ -85,6 +119,13 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Pos() types.Position  { return es.Token.Start }
+func (es *ExpressionStatement) End() types.Position {
+	if es.Expression != nil {
+		return es.Expression.End()
+	}
+	return es.Token.End
+}
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
 		return es.Expression.String()
@@ -92,15 +133,132 @@ func (es *ExpressionStatement) String() string {
 	return ""
 }
 
+type WhileStatement struct {
+	Token     types.Token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode()       {}
+func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Literal }
+func (ws *WhileStatement) Pos() types.Position  { return ws.Token.Start }
+func (ws *WhileStatement) End() types.Position  { return ws.Body.End() }
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("while (")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(") ")
+	out.WriteString(ws.Body.String())
+	return out.String()
+}
+
+type ForStatement struct {
+	Token     types.Token
+	Init      Statement
+	Condition Expression
+	Update    Expression
+	Body      *BlockStatement
+}
+
+func (fs *ForStatement) statementNode()       {}
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) Pos() types.Position  { return fs.Token.Start }
+func (fs *ForStatement) End() types.Position  { return fs.Body.End() }
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("for (")
+	if fs.Init != nil {
+		out.WriteString(strings.TrimSuffix(fs.Init.String(), ";"))
+	}
+	out.WriteString("; ")
+	if fs.Condition != nil {
+		out.WriteString(fs.Condition.String())
+	}
+	out.WriteString("; ")
+	if fs.Update != nil {
+		out.WriteString(fs.Update.String())
+	}
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+	return out.String()
+}
+
+type ForOfStatement struct {
+	Token    types.Token
+	Name     *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForOfStatement) statementNode()       {}
+func (fs *ForOfStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForOfStatement) Pos() types.Position  { return fs.Token.Start }
+func (fs *ForOfStatement) End() types.Position  { return fs.Body.End() }
+func (fs *ForOfStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("for (let ")
+	out.WriteString(fs.Name.String())
+	out.WriteString(" of ")
+	out.WriteString(fs.Iterable.String())
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+	return out.String()
+}
+
+type ForInStatement struct {
+	Token  types.Token
+	Name   *Identifier
+	Object Expression
+	Body   *BlockStatement
+}
+
+func (fs *ForInStatement) statementNode()       {}
+func (fs *ForInStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForInStatement) Pos() types.Position  { return fs.Token.Start }
+func (fs *ForInStatement) End() types.Position  { return fs.Body.End() }
+func (fs *ForInStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString("for (let ")
+	out.WriteString(fs.Name.String())
+	out.WriteString(" in ")
+	out.WriteString(fs.Object.String())
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+	return out.String()
+}
+
+type BreakStatement struct {
+	Token types.Token
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) Pos() types.Position  { return bs.Token.Start }
+func (bs *BreakStatement) End() types.Position  { return bs.Token.End }
+func (bs *BreakStatement) String() string       { return "break;" }
+
+type ContinueStatement struct {
+	Token types.Token
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) Pos() types.Position  { return cs.Token.Start }
+func (cs *ContinueStatement) End() types.Position  { return cs.Token.End }
+func (cs *ContinueStatement) String() string       { return "continue;" }
+
 type ClassStatement struct {
 	Token      types.Token
 	Name       *Identifier
 	SuperClass *Identifier
 	Methods    []*FunctionLiteral
+	Rbrace     types.Token
 }
 
 func (cs *ClassStatement) statementNode()       {}
 func (cs *ClassStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ClassStatement) Pos() types.Position  { return cs.Token.Start }
+func (cs *ClassStatement) End() types.Position  { return cs.Rbrace.End }
 func (cs *ClassStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString("class ")
@@ -117,6 +275,28 @@ func (cs *ClassStatement) String() string {
 	return out.String()
 }
 
+type BadStatement struct {
+	From types.Position
+	To   types.Position
+}
+
+func (bs *BadStatement) statementNode()       {}
+func (bs *BadStatement) TokenLiteral() string { return "" }
+func (bs *BadStatement) Pos() types.Position  { return bs.From }
+func (bs *BadStatement) End() types.Position  { return bs.To }
+func (bs *BadStatement) String() string       { return "<bad statement>" }
+
+type BadExpression struct {
+	From types.Position
+	To   types.Position
+}
+
+func (be *BadExpression) expressionNode()      {}
+func (be *BadExpression) TokenLiteral() string { return "" }
+func (be *BadExpression) Pos() types.Position  { return be.From }
+func (be *BadExpression) End() types.Position  { return be.To }
+func (be *BadExpression) String() string       { return "<bad expression>" }
+
 type Identifier struct {
 	Token types.Token
 	Value string
@@ -124,6 +304,8 @@ type Identifier struct {
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Pos() types.Position  { return i.Token.Start }
+func (i *Identifier) End() types.Position  { return i.Token.End }
 func (i *Identifier) String() string       { return i.Value }
 
 type IntegerLiteral struct {
@@ -133,8 +315,32 @@ type IntegerLiteral struct {
 
 func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
+func (il *IntegerLiteral) Pos() types.Position  { return il.Token.Start }
+func (il *IntegerLiteral) End() types.Position  { return il.Token.End }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
+type BigIntegerLiteral struct {
+	Token types.Token
+	Value *big.Int
+}
+
+func (bl *BigIntegerLiteral) expressionNode()      {}
+func (bl *BigIntegerLiteral) TokenLiteral() string { return bl.Token.Literal }
+func (bl *BigIntegerLiteral) Pos() types.Position  { return bl.Token.Start }
+func (bl *BigIntegerLiteral) End() types.Position  { return bl.Token.End }
+func (bl *BigIntegerLiteral) String() string       { return bl.Token.Literal }
+
+type FloatLiteral struct {
+	Token types.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) Pos() types.Position  { return fl.Token.Start }
+func (fl *FloatLiteral) End() types.Position  { return fl.Token.End }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+
 type StringLiteral struct {
 	Token types.Token
 	Value string
@@ -142,15 +348,20 @@ type StringLiteral struct {
 
 func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) Pos() types.Position  { return sl.Token.Start }
+func (sl *StringLiteral) End() types.Position  { return sl.Token.End }
 func (sl *StringLiteral) String() string       { return sl.Token.Literal }
 
 type ArrayLiteral struct {
 	Token    types.Token
 	Elements []Expression
+	Rbracket types.Token
 }
 
 func (al *ArrayLiteral) expressionNode()      {}
 func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Pos() types.Position  { return al.Token.Start }
+func (al *ArrayLiteral) End() types.Position  { return al.Rbracket.End }
 func (al *ArrayLiteral) String() string {
 	var out bytes.Buffer
 	elements := []string{}
@@ -164,12 +375,15 @@ func (al *ArrayLiteral) String() string {
 }
 
 type ObjectLiteral struct {
-	Token types.Token
-	Pairs map[Expression]Expression
+	Token  types.Token
+	Pairs  map[Expression]Expression
+	Rbrace types.Token
 }
 
 func (ol *ObjectLiteral) expressionNode()      {}
 func (ol *ObjectLiteral) TokenLiteral() string { return ol.Token.Literal }
+func (ol *ObjectLiteral) Pos() types.Position  { return ol.Token.Start }
+func (ol *ObjectLiteral) End() types.Position  { return ol.Rbrace.End }
 func (ol *ObjectLiteral) String() string {
 	var out bytes.Buffer
 	pairs := []string{}
@@ -183,13 +397,16 @@ func (ol *ObjectLiteral) String() string {
 }
 
 type IndexExpression struct {
-	Token types.Token
-	Left  Expression
-	Index Expression
+	Token    types.Token
+	Left     Expression
+	Index    Expression
+	Rbracket types.Token
 }
 
 func (ie *IndexExpression) expressionNode()      {}
 func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) Pos() types.Position  { return ie.Left.Pos() }
+func (ie *IndexExpression) End() types.Position  { return ie.Rbracket.End }
 func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -208,6 +425,13 @@ type PropertyExpression struct {
 
 func (pe *PropertyExpression) expressionNode()      {}
 func (pe *PropertyExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PropertyExpression) Pos() types.Position  { return pe.Object.Pos() }
+func (pe *PropertyExpression) End() types.Position {
+	if pe.Property != nil {
+		return pe.Property.End()
+	}
+	return pe.Token.End
+}
 func (pe *PropertyExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString(pe.Object.String())
@@ -217,29 +441,68 @@ func (pe *PropertyExpression) String() string {
 }
 
 type AssignmentExpression struct {
-	Token types.Token
-	Left  Expression
-	Value Expression
+	Token    types.Token
+	Left     Expression
+	Operator string
+	Value    Expression
 }
 
 func (ae *AssignmentExpression) expressionNode()      {}
 func (ae *AssignmentExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AssignmentExpression) Pos() types.Position  { return ae.Left.Pos() }
+func (ae *AssignmentExpression) End() types.Position {
+	if ae.Value != nil {
+		return ae.Value.End()
+	}
+	return ae.Token.End
+}
 func (ae *AssignmentExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString(ae.Left.String())
-	out.WriteString(" = ")
+	out.WriteString(" " + ae.Operator + " ")
 	out.WriteString(ae.Value.String())
 	return out.String()
 }
 
+type UpdateExpression struct {
+	Token    types.Token
+	Operator string
+	Target   Expression
+	Prefix   bool
+}
+
+func (ue *UpdateExpression) expressionNode()      {}
+func (ue *UpdateExpression) TokenLiteral() string { return ue.Token.Literal }
+func (ue *UpdateExpression) Pos() types.Position {
+	if ue.Prefix {
+		return ue.Token.Start
+	}
+	return ue.Target.Pos()
+}
+func (ue *UpdateExpression) End() types.Position {
+	if ue.Prefix {
+		return ue.Target.End()
+	}
+	return ue.Token.End
+}
+func (ue *UpdateExpression) String() string {
+	if ue.Prefix {
+		return "(" + ue.Operator + ue.Target.String() + ")"
+	}
+	return "(" + ue.Target.String() + ue.Operator + ")"
+}
+
 type NewExpression struct {
 	Token     types.Token
 	Class     Expression
 	Arguments []Expression
+	Rparen    types.Token
 }
 
 func (ne *NewExpression) expressionNode()      {}
 func (ne *NewExpression) TokenLiteral() string { return ne.Token.Literal }
+func (ne *NewExpression) Pos() types.Position  { return ne.Token.Start }
+func (ne *NewExpression) End() types.Position  { return ne.Rparen.End }
 func (ne *NewExpression) String() string {
 	var out bytes.Buffer
 	args := []string{}
@@ -260,6 +523,8 @@ type ThisExpression struct {
 
 func (te *ThisExpression) expressionNode()      {}
 func (te *ThisExpression) TokenLiteral() string { return te.Token.Literal }
+func (te *ThisExpression) Pos() types.Position  { return te.Token.Start }
+func (te *ThisExpression) End() types.Position  { return te.Token.End }
 func (te *ThisExpression) String() string       { return "this" }
 
 type SuperExpression struct {
@@ -268,6 +533,8 @@ type SuperExpression struct {
 
 func (se *SuperExpression) expressionNode()      {}
 func (se *SuperExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SuperExpression) Pos() types.Position  { return se.Token.Start }
+func (se *SuperExpression) End() types.Position  { return se.Token.End }
 func (se *SuperExpression) String() string       { return "super" }
 
 type NullExpression struct {
@@ -276,6 +543,8 @@ type NullExpression struct {
 
 func (ne *NullExpression) expressionNode()      {}
 func (ne *NullExpression) TokenLiteral() string { return ne.Token.Literal }
+func (ne *NullExpression) Pos() types.Position  { return ne.Token.Start }
+func (ne *NullExpression) End() types.Position  { return ne.Token.End }
 func (ne *NullExpression) String() string       { return "null" }
 
 type PrefixExpression struct {
@@ -286,6 +555,13 @@ type PrefixExpression struct {
 
 func (pe *PrefixExpression) expressionNode()      {}
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) Pos() types.Position  { return pe.Token.Start }
+func (pe *PrefixExpression) End() types.Position {
+	if pe.Right != nil {
+		return pe.Right.End()
+	}
+	return pe.Token.End
+}
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -304,6 +580,13 @@ type InfixExpression struct {
 
 func (oe *InfixExpression) expressionNode()      {}
 func (oe *InfixExpression) TokenLiteral() string { return oe.Token.Literal }
+func (oe *InfixExpression) Pos() types.Position  { return oe.Left.Pos() }
+func (oe *InfixExpression) End() types.Position {
+	if oe.Right != nil {
+		return oe.Right.End()
+	}
+	return oe.Token.End
+}
 func (oe *InfixExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -321,23 +604,42 @@ type Boolean struct {
 
 func (b *Boolean) expressionNode()      {}
 func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
+func (b *Boolean) Pos() types.Position  { return b.Token.Start }
+func (b *Boolean) End() types.Position  { return b.Token.End }
 func (b *Boolean) String() string       { return b.Token.Literal }
 
 type IfExpression struct {
 	Token       types.Token
 	Condition   Expression
 	Consequence *BlockStatement
+	ElseIfs     []*ElseIfBranch
 	Alternative *BlockStatement
 }
 
 func (ie *IfExpression) expressionNode()      {}
 func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) Pos() types.Position  { return ie.Token.Start }
+func (ie *IfExpression) End() types.Position {
+	if ie.Alternative != nil {
+		return ie.Alternative.End()
+	}
+	if len(ie.ElseIfs) > 0 {
+		return ie.ElseIfs[len(ie.ElseIfs)-1].End()
+	}
+	if ie.Consequence != nil {
+		return ie.Consequence.End()
+	}
+	return ie.Token.End
+}
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("if")
 	out.WriteString(ie.Condition.String())
 	out.WriteString(" ")
 	out.WriteString(ie.Consequence.String())
+	for _, branch := range ie.ElseIfs {
+		out.WriteString(branch.String())
+	}
 	if ie.Alternative != nil {
 		out.WriteString("else ")
 		out.WriteString(ie.Alternative.String())
@@ -345,13 +647,34 @@ func (ie *IfExpression) String() string {
 	return out.String()
 }
 
+type ElseIfBranch struct {
+	Token       types.Token
+	Condition   Expression
+	Consequence *BlockStatement
+}
+
+func (eb *ElseIfBranch) TokenLiteral() string { return eb.Token.Literal }
+func (eb *ElseIfBranch) Pos() types.Position  { return eb.Token.Start }
+func (eb *ElseIfBranch) End() types.Position  { return eb.Consequence.End() }
+func (eb *ElseIfBranch) String() string {
+	var out bytes.Buffer
+	out.WriteString("else if")
+	out.WriteString(eb.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(eb.Consequence.String())
+	return out.String()
+}
+
 type BlockStatement struct {
 	Token      types.Token
 	Statements []Statement
+	Rbrace     types.Token
 }
 
 func (bs *BlockStatement) statementNode()       {}
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) Pos() types.Position  { return bs.Token.Start }
+func (bs *BlockStatement) End() types.Position  { return bs.Rbrace.End }
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
 	for _, s := range bs.Statements {
@@ -368,6 +691,13 @@ type FunctionLiteral struct {
 
 func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) Pos() types.Position  { return fl.Token.Start }
+func (fl *FunctionLiteral) End() types.Position {
+	if fl.Body != nil {
+		return fl.Body.End()
+	}
+	return fl.Token.End
+}
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 	params := []string{}
@@ -386,10 +716,13 @@ type CallExpression struct {
 	Token     types.Token
 	Function  Expression
 	Arguments []Expression
+	Rparen    types.Token
 }
 
 func (ce *CallExpression) expressionNode()      {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Pos() types.Position  { return ce.Function.Pos() }
+func (ce *CallExpression) End() types.Position  { return ce.Rparen.End }
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
 	args := []string{}
@@ -0,0 +1,74 @@
+package diag
+
+import (
+	"fmt"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/types"
+	"strings"
+	"unicode/utf8"
+)
+
+const contextLines = 2
+
+func CodeFrame(source string, start, end types.Position) string {
+	if !start.IsValid() {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimSuffix(source, "\n"), "\n")
+	first := max(start.Line-contextLines, 1)
+	last := max(min(start.Line+contextLines, len(lines)), start.Line)
+	width := len(fmt.Sprint(last))
+
+	var out strings.Builder
+	for n := first; n <= last; n++ {
+		text := ""
+		if n <= len(lines) {
+			text = strings.TrimRight(lines[n-1], "\r")
+		}
+
+		marker := " "
+		if n == start.Line {
+			marker = ">"
+		}
+		fmt.Fprintf(&out, "%s %*d | %s\n", marker, width, n, text)
+
+		if n == start.Line {
+			fmt.Fprintf(&out, "  %*s | %s%s\n", width, "", padding(text, start.Column), underline(text, start, end))
+		}
+	}
+
+	return out.String()
+}
+
+// padding and underline work in characters, matching how the lexer counts
+// columns.
+func padding(text string, column int) string {
+	runes := []rune(text)
+	var out strings.Builder
+	for i := 0; i < column-1; i++ {
+		if i < len(runes) && runes[i] == '\t' {
+			out.WriteByte('\t')
+		} else {
+			out.WriteByte(' ')
+		}
+	}
+	return out.String()
+}
+
+func underline(text string, start, end types.Position) string {
+	width := 1
+	if end.Line == start.Line && end.Column > start.Column {
+		width = end.Column - start.Column
+	} else if length := utf8.RuneCountInString(text); end.Line > start.Line && length+1 > start.Column {
+		width = length + 1 - start.Column
+	}
+	return strings.Repeat("^", width)
+}
+
+func SourceLine(source string, line int) string {
+	lines := strings.Split(source, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimRight(lines[line-1], "\r")
+}
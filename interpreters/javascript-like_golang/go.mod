@@ -1,3 +1,3 @@
-module main
+module github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang
 
 go 1.21
\ No newline at end of file
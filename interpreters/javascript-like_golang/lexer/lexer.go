@@ -1,7 +1,8 @@
 package lexer
 
 import (
-	"main/types"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/types"
+	"unicode/utf8"
 )
 
 type Lexer struct {
@@ -9,24 +10,46 @@ type Lexer struct {
 	position     int
 	readPosition int
 	ch           byte
+	line         int
+	column       int
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewAt(input, 1)
+}
+
+// NewAt lexes input as if it started on the given line of a larger source,
+// such as one entry of a repl session. Offsets stay relative to input.
+func NewAt(input string, line int) *Lexer {
+	l := &Lexer{input: input, line: line}
 	l.readChar()
 	return l
 }
 
 func (l *Lexer) readChar() {
-	if l.readPosition >= len(l.input) {
-		l.ch = 0
-	} else {
-		l.ch = l.input[l.readPosition]
+	var next byte
+	if l.readPosition < len(l.input) {
+		next = l.input[l.readPosition]
+	}
+
+	// columns count characters, so the continuation bytes of a multi-byte
+	// UTF-8 sequence stay on the column of its first byte
+	if l.ch == '\n' {
+		l.line++
+		l.column = 1
+	} else if utf8.RuneStart(next) {
+		l.column++
 	}
+
+	l.ch = next
 	l.position = l.readPosition
 	l.readPosition += 1
 }
 
+func (l *Lexer) pos() types.Position {
+	return types.Position{Offset: l.position, Line: l.line, Column: l.column}
+}
+
 func (l *Lexer) peekChar() byte {
 	if l.readPosition >= len(l.input) {
 		return 0
@@ -35,10 +58,18 @@ func (l *Lexer) peekChar() byte {
 	}
 }
 
+func (l *Lexer) peekCharAt(offset int) byte {
+	if l.position+offset >= len(l.input) {
+		return 0
+	}
+	return l.input[l.position+offset]
+}
+
 func (l *Lexer) NextToken() types.Token {
 	var tok types.Token
 
 	l.skipWhitespace()
+	start := l.pos()
 
 	switch l.ch {
 	case '=':
@@ -46,22 +77,28 @@ func (l *Lexer) NextToken() types.Token {
 			ch := l.ch
 			l.readChar()
 			literal := string(ch) + string(l.ch)
-			tok = types.NewToken(types.EQ, literal, l.position)
+			tok = newToken(types.EQ, literal)
 		} else {
-			tok = types.NewToken(types.ASSIGN, string(l.ch), l.position)
+			tok = newToken(types.ASSIGN, string(l.ch))
 		}
 	case '+':
-		tok = types.NewToken(types.PLUS, string(l.ch), l.position)
+		tok = l.readOperator(types.PLUS, map[string]types.TokenType{
+			"++": types.INCREMENT,
+			"+=": types.PLUS_ASSIGN,
+		})
 	case '-':
-		tok = types.NewToken(types.MINUS, string(l.ch), l.position)
+		tok = l.readOperator(types.MINUS, map[string]types.TokenType{
+			"--": types.DECREMENT,
+			"-=": types.MINUS_ASSIGN,
+		})
 	case '!':
 		if l.peekChar() == '=' {
 			ch := l.ch
 			l.readChar()
 			literal := string(ch) + string(l.ch)
-			tok = types.NewToken(types.NOT_EQ, literal, l.position)
+			tok = newToken(types.NOT_EQ, literal)
 		} else {
-			tok = types.NewToken(types.BANG, string(l.ch), l.position)
+			tok = newToken(types.BANG, string(l.ch))
 		}
 	case '/':
 		if l.peekChar() == '/' {
@@ -71,62 +108,121 @@ func (l *Lexer) NextToken() types.Token {
 			l.readBlockComment()
 			return l.NextToken()
 		} else {
-			tok = types.NewToken(types.SLASH, string(l.ch), l.position)
+			tok = l.readOperator(types.SLASH, map[string]types.TokenType{
+				"/=": types.SLASH_ASSIGN,
+			})
 		}
 	case '*':
-		tok = types.NewToken(types.ASTERISK, string(l.ch), l.position)
+		tok = l.readOperator(types.ASTERISK, map[string]types.TokenType{
+			"**":  types.POWER,
+			"*=":  types.ASTERISK_ASSIGN,
+			"**=": types.POWER_ASSIGN,
+		})
+	case '%':
+		tok = l.readOperator(types.PERCENT, map[string]types.TokenType{
+			"%=": types.PERCENT_ASSIGN,
+		})
 	case '<':
-		tok = types.NewToken(types.LT, string(l.ch), l.position)
+		tok = l.readOperator(types.LT, map[string]types.TokenType{
+			"<=":  types.LT_EQ,
+			"<<":  types.SHIFT_LEFT,
+			"<<=": types.SHIFT_LEFT_ASSIGN,
+		})
 	case '>':
-		tok = types.NewToken(types.GT, string(l.ch), l.position)
+		tok = l.readOperator(types.GT, map[string]types.TokenType{
+			">=":  types.GT_EQ,
+			">>":  types.SHIFT_RIGHT,
+			">>=": types.SHIFT_RIGHT_ASSIGN,
+		})
+	case '&':
+		tok = l.readOperator(types.AMPERSAND, map[string]types.TokenType{
+			"&&":  types.AND,
+			"&=":  types.AMPERSAND_ASSIGN,
+			"&&=": types.AND_ASSIGN,
+		})
+	case '|':
+		tok = l.readOperator(types.PIPE, map[string]types.TokenType{
+			"||":  types.OR,
+			"|=":  types.PIPE_ASSIGN,
+			"||=": types.OR_ASSIGN,
+		})
+	case '?':
+		tok = l.readOperator(types.ILLEGAL, map[string]types.TokenType{
+			"??":  types.NULLISH,
+			"??=": types.NULLISH_ASSIGN,
+		})
+	case '^':
+		tok = l.readOperator(types.CARET, map[string]types.TokenType{
+			"^=": types.CARET_ASSIGN,
+		})
+	case '~':
+		tok = newToken(types.TILDE, string(l.ch))
 	case ';':
-		tok = types.NewToken(types.SEMICOLON, string(l.ch), l.position)
+		tok = newToken(types.SEMICOLON, string(l.ch))
 	case ',':
-		tok = types.NewToken(types.COMMA, string(l.ch), l.position)
+		tok = newToken(types.COMMA, string(l.ch))
 	case ':':
-		tok = types.NewToken(types.COLON, string(l.ch), l.position)
+		tok = newToken(types.COLON, string(l.ch))
 	case '.':
-		tok = types.NewToken(types.DOT, string(l.ch), l.position)
+		tok = newToken(types.DOT, string(l.ch))
 	case '{':
-		tok = types.NewToken(types.LBRACE, string(l.ch), l.position)
+		tok = newToken(types.LBRACE, string(l.ch))
 	case '}':
-		tok = types.NewToken(types.RBRACE, string(l.ch), l.position)
+		tok = newToken(types.RBRACE, string(l.ch))
 	case '[':
-		tok = types.NewToken(types.LBRACKET, string(l.ch), l.position)
+		tok = newToken(types.LBRACKET, string(l.ch))
 	case ']':
-		tok = types.NewToken(types.RBRACKET, string(l.ch), l.position)
+		tok = newToken(types.RBRACKET, string(l.ch))
 	case '(':
-		tok = types.NewToken(types.LPAREN, string(l.ch), l.position)
+		tok = newToken(types.LPAREN, string(l.ch))
 	case ')':
-		tok = types.NewToken(types.RPAREN, string(l.ch), l.position)
+		tok = newToken(types.RPAREN, string(l.ch))
 	case '"':
-		tok.Type = types.STRING
-		tok.Literal = l.readString()
-		tok.Position = l.position
+		tok = newToken(types.STRING, l.readString())
 	case 0:
-		tok.Literal = ""
-		tok.Type = types.EOF
-		tok.Position = l.position
+		return types.NewToken(types.EOF, "", start, start)
 	default:
 		if isLetter(l.ch) {
-			tok.Literal = l.readIdentifier()
-			tok.Type = lookupIdent(tok.Literal)
-			tok.Position = l.position
-			return tok
+			literal := l.readIdentifier()
+			return types.NewToken(lookupIdent(literal), literal, start, l.pos())
 		} else if isDigit(l.ch) {
-			tok.Type = types.INT
-			tok.Literal = l.readNumber()
-			tok.Position = l.position
-			return tok
+			tokenType, literal := l.readNumber()
+			return types.NewToken(tokenType, literal, start, l.pos())
 		} else {
-			tok = types.NewToken(types.ILLEGAL, string(l.ch), l.position)
+			tok = newToken(types.ILLEGAL, string(l.ch))
 		}
 	}
 
 	l.readChar()
+	tok.Start = start
+	tok.End = l.pos()
 	return tok
 }
 
+func newToken(tokenType types.TokenType, literal string) types.Token {
+	return types.Token{Type: tokenType, Literal: literal}
+}
+
+// readOperator consumes the longest of the given operators that starts at the
+// current character, falling back to the single character token.
+func (l *Lexer) readOperator(single types.TokenType, longer map[string]types.TokenType) types.Token {
+	for n := 3; n > 1; n-- {
+		if l.position+n > len(l.input) {
+			continue
+		}
+
+		literal := l.input[l.position : l.position+n]
+		if tokenType, ok := longer[literal]; ok {
+			for i := 1; i < n; i++ {
+				l.readChar()
+			}
+			return newToken(tokenType, literal)
+		}
+	}
+
+	return newToken(single, string(l.ch))
+}
+
 func (l *Lexer) skipWhitespace() {
 	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
 		l.readChar()
@@ -141,12 +237,53 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readNumber() string {
+func (l *Lexer) readNumber() (types.TokenType, string) {
 	position := l.position
-	for isDigit(l.ch) {
+	tokenType := types.INT
+
+	if l.ch == '0' && isBasePrefix(l.peekChar()) {
+		l.readChar()
+		l.readChar()
+		l.readAlphanumeric()
+		return tokenType, l.input[position:l.position]
+	}
+
+	l.readDigits()
+
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = types.FLOAT
+		l.readChar()
+		l.readDigits()
+	}
+
+	if l.ch == 'e' || l.ch == 'E' {
+		next := l.peekChar()
+		if isDigit(next) || ((next == '+' || next == '-') && isDigit(l.peekCharAt(2))) {
+			tokenType = types.FLOAT
+			l.readChar()
+			if l.ch == '+' || l.ch == '-' {
+				l.readChar()
+			}
+			l.readDigits()
+		}
+	}
+
+	// Swallow trailing letters so that "12abc" is reported as one malformed literal.
+	l.readAlphanumeric()
+
+	return tokenType, l.input[position:l.position]
+}
+
+func (l *Lexer) readDigits() {
+	for isDigit(l.ch) || l.ch == '_' {
+		l.readChar()
+	}
+}
+
+func (l *Lexer) readAlphanumeric() {
+	for isLetter(l.ch) || isDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[position:l.position]
 }
 
 func (l *Lexer) readString() string {
@@ -190,16 +327,27 @@ func isDigit(ch byte) bool {
 	return '0' <= ch && ch <= '9'
 }
 
+func isBasePrefix(ch byte) bool {
+	switch ch {
+	case 'x', 'X', 'b', 'B', 'o', 'O':
+		return true
+	}
+	return false
+}
+
 func lookupIdent(ident string) types.TokenType {
 	keywords := map[string]types.TokenType{
-		"func":   types.FUNCTION,
-		"let":    types.LET,
-		"true":   types.TRUE,
-		"false":  types.FALSE,
-		"if":     types.IF,
-		"else":   types.ELSE,
-		"return": types.RETURN,
-		"print":  types.PRINT,
+		"func":     types.FUNCTION,
+		"let":      types.LET,
+		"true":     types.TRUE,
+		"false":    types.FALSE,
+		"if":       types.IF,
+		"else":     types.ELSE,
+		"while":    types.WHILE,
+		"for":      types.FOR,
+		"break":    types.BREAK,
+		"continue": types.CONTINUE,
+		"return":   types.RETURN,
 		/*"class":   types.CLASS,
 		"new":     types.NEW,
 		"this":    types.THIS,
@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"fmt"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/diag"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/types"
+	"strings"
+)
+
+type ParseError struct {
+	Message  string
+	Pos      types.Position
+	End      types.Position
+	Expected []types.TokenType
+	Actual   types.Token
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
+}
+
+func (e *ParseError) Render(filename, source string) string {
+	var out strings.Builder
+	if filename != "" {
+		out.WriteString(filename + ":")
+	}
+	out.WriteString(e.Error())
+	if frame := diag.CodeFrame(source, e.Pos, e.End); frame != "" {
+		out.WriteString("\n\n")
+		out.WriteString(strings.TrimSuffix(frame, "\n"))
+	}
+	return out.String()
+}
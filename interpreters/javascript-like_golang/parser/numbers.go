@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hasLeadingZero reports a decimal literal such as 010, which legacy JS reads
+// as octal; it is rejected rather than given either meaning silently.
+func hasLeadingZero(literal string) bool {
+	return len(literal) > 1 && literal[0] == '0' && (isDigit(literal[1]) || literal[1] == '_')
+}
+
+func leadingZeroError(literal string) string {
+	return fmt.Sprintf("decimal literal %q cannot start with 0; use the 0o prefix for octal literals", literal)
+}
+
+func isDigit(ch byte) bool {
+	return '0' <= ch && ch <= '9'
+}
+
+func describeNumberError(literal string) string {
+	name, digits, valid := "decimal", literal, "0123456789_.eE+-"
+
+	if len(literal) > 1 && literal[0] == '0' {
+		switch literal[1] {
+		case 'x', 'X':
+			name, digits, valid = "hexadecimal", literal[2:], "0123456789abcdefABCDEF_"
+		case 'b', 'B':
+			name, digits, valid = "binary", literal[2:], "01_"
+		case 'o', 'O':
+			name, digits, valid = "octal", literal[2:], "01234567_"
+		}
+	}
+
+	if digits == "" || strings.Trim(digits, "_") == "" {
+		return fmt.Sprintf("%s literal %q has no digits", name, literal)
+	}
+
+	for _, ch := range digits {
+		if !strings.ContainsRune(valid, ch) {
+			return fmt.Sprintf("invalid digit %q in %s literal %q", ch, name, literal)
+		}
+	}
+
+	for _, bad := range []string{"__", "_.", "._", "_e", "_E", "e_", "E_", "+_", "-_"} {
+		if strings.Contains(digits, bad) {
+			return fmt.Sprintf("'_' must separate successive digits in %q", literal)
+		}
+	}
+	if strings.HasSuffix(digits, "_") {
+		return fmt.Sprintf("'_' must separate successive digits in %q", literal)
+	}
+
+	if strings.ContainsAny(digits[len(digits)-1:], "eE+-") {
+		return fmt.Sprintf("exponent has no digits in %q", literal)
+	}
+
+	return fmt.Sprintf("malformed number literal %q", literal)
+}
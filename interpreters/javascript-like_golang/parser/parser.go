@@ -1,40 +1,82 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
-	"main/ast"
-	"main/lexer"
-	"main/types"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/ast"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/lexer"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/types"
+	"math/big"
 	"strconv"
 )
 
 const (
 	_ int = iota
 	LOWEST
+	ASSIGN
+	NULLISH
+	LOGICAL_OR
+	LOGICAL_AND
+	BITWISE_OR
+	BITWISE_XOR
+	BITWISE_AND
 	EQUALS
 	LESSGREATER
+	SHIFT
 	SUM
 	PRODUCT
 	PREFIX
+	POWER
+	POSTFIX
 	CALL
 	INDEX
 )
 
 var precedences = map[types.TokenType]int{
-	types.ASSIGN:   LOWEST,
-	types.EQ:       EQUALS,
-	types.NOT_EQ:   EQUALS,
-	types.LT:       LESSGREATER,
-	types.GT:       LESSGREATER,
-	types.PLUS:     SUM,
-	types.MINUS:    SUM,
-	types.SLASH:    PRODUCT,
-	types.ASTERISK: PRODUCT,
-	types.LPAREN:   CALL,
-	types.LBRACKET: INDEX,
-	types.DOT:      INDEX,
+	types.ASSIGN:             ASSIGN,
+	types.PLUS_ASSIGN:        ASSIGN,
+	types.MINUS_ASSIGN:       ASSIGN,
+	types.ASTERISK_ASSIGN:    ASSIGN,
+	types.SLASH_ASSIGN:       ASSIGN,
+	types.PERCENT_ASSIGN:     ASSIGN,
+	types.POWER_ASSIGN:       ASSIGN,
+	types.AMPERSAND_ASSIGN:   ASSIGN,
+	types.PIPE_ASSIGN:        ASSIGN,
+	types.CARET_ASSIGN:       ASSIGN,
+	types.SHIFT_LEFT_ASSIGN:  ASSIGN,
+	types.SHIFT_RIGHT_ASSIGN: ASSIGN,
+	types.AND_ASSIGN:         ASSIGN,
+	types.OR_ASSIGN:          ASSIGN,
+	types.NULLISH_ASSIGN:     ASSIGN,
+	types.NULLISH:            NULLISH,
+	types.OR:                 LOGICAL_OR,
+	types.AND:                LOGICAL_AND,
+	types.PIPE:               BITWISE_OR,
+	types.CARET:              BITWISE_XOR,
+	types.AMPERSAND:          BITWISE_AND,
+	types.EQ:                 EQUALS,
+	types.NOT_EQ:             EQUALS,
+	types.LT:                 LESSGREATER,
+	types.GT:                 LESSGREATER,
+	types.LT_EQ:              LESSGREATER,
+	types.GT_EQ:              LESSGREATER,
+	types.SHIFT_LEFT:         SHIFT,
+	types.SHIFT_RIGHT:        SHIFT,
+	types.PLUS:               SUM,
+	types.MINUS:              SUM,
+	types.SLASH:              PRODUCT,
+	types.ASTERISK:           PRODUCT,
+	types.PERCENT:            PRODUCT,
+	types.POWER:              POWER,
+	types.INCREMENT:          POSTFIX,
+	types.DECREMENT:          POSTFIX,
+	types.LPAREN:             CALL,
+	types.LBRACKET:           INDEX,
+	types.DOT:                INDEX,
 }
 
+const maxNestingDepth = 10000
+
 type (
 	prefixParseFn func() ast.Expression
 	infixParseFn  func(ast.Expression) ast.Expression
@@ -43,11 +85,24 @@ type (
 type Parser struct {
 	l *lexer.Lexer
 
-	errors []string
+	errors []*ParseError
 
 	curToken  types.Token
 	peekToken types.Token
 
+	panicking bool
+	loopDepth int
+
+	// braceDepth is the brace balance of the tokens before curToken and
+	// blockDepth the number of block statements being parsed; synchronize
+	// uses them to tell which } closes the block around a bad statement.
+	braceDepth int
+	blockDepth int
+
+	// nesting counts the expressions and blocks being parsed, so deeply
+	// nested input is reported instead of overflowing the Go stack.
+	nesting int
+
 	prefixParseFns map[types.TokenType]prefixParseFn
 	infixParseFns  map[types.TokenType]infixParseFn
 }
@@ -55,21 +110,24 @@ type Parser struct {
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
 		l:      l,
-		errors: []string{},
+		errors: []*ParseError{},
 	}
 
 	p.prefixParseFns = make(map[types.TokenType]prefixParseFn)
 	p.registerPrefix(types.IDENT, p.parseIdentifier)
 	p.registerPrefix(types.INT, p.parseIntegerLiteral)
+	p.registerPrefix(types.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(types.STRING, p.parseStringLiteral)
 	p.registerPrefix(types.BANG, p.parsePrefixExpression)
 	p.registerPrefix(types.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(types.TILDE, p.parsePrefixExpression)
+	p.registerPrefix(types.INCREMENT, p.parsePrefixUpdateExpression)
+	p.registerPrefix(types.DECREMENT, p.parsePrefixUpdateExpression)
 	p.registerPrefix(types.TRUE, p.parseBoolean)
 	p.registerPrefix(types.FALSE, p.parseBoolean)
 	p.registerPrefix(types.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(types.IF, p.parseIfExpression)
 	p.registerPrefix(types.FUNCTION, p.parseFunctionLiteral)
-	p.registerPrefix(types.PRINT, p.parsePrintStatement)
 	p.registerPrefix(types.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(types.LBRACE, p.parseObjectLiteral)
 	p.registerPrefix(types.NEW, p.parseNewExpression)
@@ -85,10 +143,38 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(types.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(types.LT, p.parseInfixExpression)
 	p.registerInfix(types.GT, p.parseInfixExpression)
+	p.registerInfix(types.LT_EQ, p.parseInfixExpression)
+	p.registerInfix(types.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(types.PERCENT, p.parseInfixExpression)
+	p.registerInfix(types.POWER, p.parseInfixExpression)
+	p.registerInfix(types.AMPERSAND, p.parseInfixExpression)
+	p.registerInfix(types.PIPE, p.parseInfixExpression)
+	p.registerInfix(types.CARET, p.parseInfixExpression)
+	p.registerInfix(types.SHIFT_LEFT, p.parseInfixExpression)
+	p.registerInfix(types.SHIFT_RIGHT, p.parseInfixExpression)
+	p.registerInfix(types.AND, p.parseInfixExpression)
+	p.registerInfix(types.OR, p.parseInfixExpression)
+	p.registerInfix(types.NULLISH, p.parseInfixExpression)
 	p.registerInfix(types.LPAREN, p.parseCallExpression)
 	p.registerInfix(types.LBRACKET, p.parseIndexExpression)
 	p.registerInfix(types.DOT, p.parsePropertyExpression)
 	p.registerInfix(types.ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(types.PLUS_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(types.MINUS_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(types.ASTERISK_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(types.SLASH_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(types.PERCENT_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(types.POWER_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(types.AMPERSAND_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(types.PIPE_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(types.CARET_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(types.SHIFT_LEFT_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(types.SHIFT_RIGHT_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(types.AND_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(types.OR_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(types.NULLISH_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(types.INCREMENT, p.parsePostfixUpdateExpression)
+	p.registerInfix(types.DECREMENT, p.parsePostfixUpdateExpression)
 
 	p.nextToken()
 	p.nextToken()
@@ -97,6 +183,13 @@ func New(l *lexer.Lexer) *Parser {
 }
 
 func (p *Parser) nextToken() {
+	switch p.curToken.Type {
+	case types.LBRACE:
+		p.braceDepth++
+	case types.RBRACE:
+		p.braceDepth--
+	}
+
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
 }
@@ -117,6 +210,19 @@ func (p *Parser) ParseProgram() *ast.Program {
 }
 
 func (p *Parser) parseStatement() ast.Statement {
+	start := p.curToken.Start
+	depth := p.braceDepth
+
+	stmt := p.parseStatementNode()
+	if p.panicking {
+		p.synchronize(depth)
+		return &ast.BadStatement{From: start, To: p.curToken.End}
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseStatementNode() ast.Statement {
 	switch p.curToken.Type {
 	case types.LET:
 		return p.parseLetStatement()
@@ -124,6 +230,14 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseReturnStatement()
 	case types.CLASS:
 		return p.parseClassStatement()
+	case types.WHILE:
+		return p.parseWhileStatement()
+	case types.FOR:
+		return p.parseForStatement()
+	case types.BREAK:
+		return p.parseBreakStatement()
+	case types.CONTINUE:
+		return p.parseContinueStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -138,6 +252,10 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
+	return p.parseLetValue(stmt)
+}
+
+func (p *Parser) parseLetValue(stmt *ast.LetStatement) *ast.LetStatement {
 	if !p.expectPeek(types.ASSIGN) {
 		return nil
 	}
@@ -167,6 +285,163 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
+func (p *Parser) parseWhileStatement() *ast.WhileStatement {
+	stmt := &ast.WhileStatement{Token: p.curToken}
+
+	if !p.expectPeek(types.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(types.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(types.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseLoopBody()
+
+	if p.peekTokenIs(types.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseForStatement() ast.Statement {
+	stmt := &ast.ForStatement{Token: p.curToken}
+
+	if !p.expectPeek(types.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	if !p.curTokenIs(types.SEMICOLON) {
+		if p.curTokenIs(types.LET) {
+			let := &ast.LetStatement{Token: p.curToken}
+			if !p.expectPeek(types.IDENT) {
+				return nil
+			}
+			let.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+			// "of" and "in" are only keywords right after the loop variable
+			if p.peekTokenIs(types.IDENT) && (p.peekToken.Literal == "of" || p.peekToken.Literal == "in") {
+				return p.parseForEachStatement(stmt.Token, let.Name)
+			}
+
+			stmt.Init = p.parseLetValue(let)
+		} else {
+			stmt.Init = p.parseExpressionStatement()
+		}
+		if p.panicking {
+			return nil
+		}
+		// the init statement consumes its own semicolon when one follows
+		if !p.curTokenIs(types.SEMICOLON) && !p.expectPeek(types.SEMICOLON) {
+			return nil
+		}
+	}
+
+	if !p.peekTokenIs(types.SEMICOLON) {
+		p.nextToken()
+		stmt.Condition = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(types.SEMICOLON) {
+		return nil
+	}
+
+	if !p.peekTokenIs(types.RPAREN) {
+		p.nextToken()
+		stmt.Update = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(types.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(types.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseLoopBody()
+
+	if p.peekTokenIs(types.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseForEachStatement(token types.Token, name *ast.Identifier) ast.Statement {
+	p.nextToken()
+	kind := p.curToken.Literal
+
+	p.nextToken()
+	collection := p.parseExpression(LOWEST)
+
+	if !p.expectPeek(types.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(types.LBRACE) {
+		return nil
+	}
+
+	body := p.parseLoopBody()
+
+	if p.peekTokenIs(types.SEMICOLON) {
+		p.nextToken()
+	}
+
+	if kind == "in" {
+		return &ast.ForInStatement{Token: token, Name: name, Object: collection, Body: body}
+	}
+	return &ast.ForOfStatement{Token: token, Name: name, Iterable: collection, Body: body}
+}
+
+func (p *Parser) parseLoopBody() *ast.BlockStatement {
+	p.loopDepth++
+	defer func() { p.loopDepth-- }()
+
+	return p.parseBlockStatement()
+}
+
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+
+	if p.loopDepth == 0 {
+		p.errorAt(p.curToken, "break outside of a loop")
+		return nil
+	}
+
+	if p.peekTokenIs(types.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
+
+	if p.loopDepth == 0 {
+		p.errorAt(p.curToken, "continue outside of a loop")
+		return nil
+	}
+
+	if p.peekTokenIs(types.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseClassStatement() *ast.ClassStatement {
 	stmt := &ast.ClassStatement{Token: p.curToken}
 
@@ -198,7 +473,7 @@ func (p *Parser) parseClassStatement() *ast.ClassStatement {
 				continue
 			}
 
-			methodName := p.curToken.Literal
+			methodToken := p.curToken
 
 			if !p.expectPeek(types.ASSIGN) {
 				p.nextToken()
@@ -211,9 +486,8 @@ func (p *Parser) parseClassStatement() *ast.ClassStatement {
 			}
 
 			method := p.parseFunctionLiteral()
-			if method != nil {
-				functionLiteral := method.(*ast.FunctionLiteral)
-				methodIdent := &ast.Identifier{Token: types.NewToken(types.IDENT, methodName, 0), Value: methodName}
+			if functionLiteral, ok := method.(*ast.FunctionLiteral); ok {
+				methodIdent := &ast.Identifier{Token: methodToken, Value: methodToken.Literal}
 				functionLiteral.Parameters = append([]*ast.Identifier{methodIdent}, functionLiteral.Parameters...)
 				stmt.Methods = append(stmt.Methods, functionLiteral)
 			}
@@ -221,6 +495,8 @@ func (p *Parser) parseClassStatement() *ast.ClassStatement {
 		p.nextToken()
 	}
 
+	stmt.Rbrace = p.curToken
+
 	return stmt
 }
 
@@ -237,10 +513,15 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 }
 
 func (p *Parser) parseExpression(precedence int) ast.Expression {
+	defer p.leave()
+	if !p.enter() {
+		return p.badExpression(p.curToken.Start)
+	}
+
 	prefix := p.prefixParseFns[p.curToken.Type]
 	if prefix == nil {
 		p.noPrefixParseFnError(p.curToken.Type)
-		return nil
+		return p.badExpression(p.curToken.Start)
 	}
 	leftExp := prefix()
 
@@ -265,11 +546,42 @@ func (p *Parser) parseIdentifier() ast.Expression {
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
+	if hasLeadingZero(p.curToken.Literal) {
+		p.errorAt(p.curToken, "%s", leadingZeroError(p.curToken.Literal))
+		return p.badExpression(lit.Token.Start)
+	}
+
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if big, ok := new(big.Int).SetString(p.curToken.Literal, 0); ok {
+			return &ast.BigIntegerLiteral{Token: p.curToken, Value: big}
+		}
+	}
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
-		return nil
+		p.errorAt(p.curToken, "%s", describeNumberError(p.curToken.Literal))
+		return p.badExpression(lit.Token.Start)
+	}
+
+	lit.Value = value
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	if hasLeadingZero(p.curToken.Literal) {
+		p.errorAt(p.curToken, "%s", leadingZeroError(p.curToken.Literal))
+		return p.badExpression(lit.Token.Start)
+	}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		p.errorAt(p.curToken, "float literal %q is out of range", p.curToken.Literal)
+		return p.badExpression(lit.Token.Start)
+	}
+	if err != nil {
+		p.errorAt(p.curToken, "%s", describeNumberError(p.curToken.Literal))
+		return p.badExpression(lit.Token.Start)
 	}
 
 	lit.Value = value
@@ -283,6 +595,7 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.curToken}
 	array.Elements = p.parseExpressionList(types.RBRACKET)
+	array.Rbracket = p.curToken
 	return array
 }
 
@@ -296,7 +609,7 @@ func (p *Parser) parseObjectLiteral() ast.Expression {
 		key := p.parseExpression(LOWEST)
 
 		if !p.expectPeek(types.COLON) {
-			return nil
+			return p.badExpression(obj.Token.Start)
 		}
 
 		p.nextToken()
@@ -305,14 +618,16 @@ func (p *Parser) parseObjectLiteral() ast.Expression {
 		obj.Pairs[key] = value
 
 		if !p.peekTokenIs(types.RBRACE) && !p.expectPeek(types.COMMA) {
-			return nil
+			return p.badExpression(obj.Token.Start)
 		}
 	}
 
 	if !p.expectPeek(types.RBRACE) {
-		return nil
+		return p.badExpression(obj.Token.Start)
 	}
 
+	obj.Rbrace = p.curToken
+
 	return obj
 }
 
@@ -320,16 +635,17 @@ func (p *Parser) parseNewExpression() ast.Expression {
 	exp := &ast.NewExpression{Token: p.curToken}
 
 	if !p.expectPeek(types.IDENT) {
-		return nil
+		return p.badExpression(exp.Token.Start)
 	}
 
 	exp.Class = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
 	if !p.expectPeek(types.LPAREN) {
-		return nil
+		return p.badExpression(exp.Token.Start)
 	}
 
 	exp.Arguments = p.parseExpressionList(types.RPAREN)
+	exp.Rparen = p.curToken
 
 	return exp
 }
@@ -367,6 +683,10 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	}
 
 	precedence := p.curPrecedence()
+	if p.curTokenIs(types.POWER) {
+		// ** is right-associative: 2 ** 3 ** 2 == 2 ** 9
+		precedence--
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 
@@ -380,9 +700,11 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	exp.Index = p.parseExpression(LOWEST)
 
 	if !p.expectPeek(types.RBRACKET) {
-		return nil
+		return p.badExpression(left.Pos())
 	}
 
+	exp.Rbracket = p.curToken
+
 	return exp
 }
 
@@ -390,7 +712,7 @@ func (p *Parser) parsePropertyExpression(left ast.Expression) ast.Expression {
 	exp := &ast.PropertyExpression{Token: p.curToken, Object: left}
 
 	if !p.expectPeek(types.IDENT) {
-		return nil
+		return p.badExpression(left.Pos())
 	}
 
 	exp.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
@@ -399,25 +721,67 @@ func (p *Parser) parsePropertyExpression(left ast.Expression) ast.Expression {
 }
 
 func (p *Parser) parseAssignmentExpression(left ast.Expression) ast.Expression {
-	exp := &ast.AssignmentExpression{Token: p.curToken, Left: left}
+	exp := &ast.AssignmentExpression{Token: p.curToken, Left: left, Operator: p.curToken.Literal}
+
+	if !isAssignable(left) {
+		p.errorAt(p.curToken, "invalid assignment target %s", left.String())
+		return p.badExpression(left.Pos())
+	}
 
+	// parsing the value one level below ASSIGN makes a = b = c right-associative
 	p.nextToken()
-	exp.Value = p.parseExpression(LOWEST)
+	exp.Value = p.parseExpression(ASSIGN - 1)
+
+	return exp
+}
+
+func (p *Parser) parsePrefixUpdateExpression() ast.Expression {
+	exp := &ast.UpdateExpression{Token: p.curToken, Operator: p.curToken.Literal, Prefix: true}
+
+	p.nextToken()
+	exp.Target = p.parseExpression(PREFIX)
+
+	if !isAssignable(exp.Target) {
+		p.errorAt(exp.Token, "invalid %s operand %s", exp.Operator, exp.Target.String())
+		return p.badExpression(exp.Token.Start)
+	}
+
+	return exp
+}
+
+func (p *Parser) parsePostfixUpdateExpression(left ast.Expression) ast.Expression {
+	exp := &ast.UpdateExpression{Token: p.curToken, Operator: p.curToken.Literal, Target: left}
+
+	if !isAssignable(left) {
+		p.errorAt(exp.Token, "invalid %s operand %s", exp.Operator, left.String())
+		return p.badExpression(left.Pos())
+	}
 
 	return exp
 }
 
+func isAssignable(exp ast.Expression) bool {
+	switch exp.(type) {
+	case *ast.Identifier, *ast.IndexExpression, *ast.PropertyExpression:
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(types.TRUE)}
 }
 
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	start := p.curToken.Start
+
 	p.nextToken()
 
 	exp := p.parseExpression(LOWEST)
 
 	if !p.expectPeek(types.RPAREN) {
-		return nil
+		return p.badExpression(start)
 	}
 
 	return exp
@@ -427,30 +791,55 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	expression := &ast.IfExpression{Token: p.curToken}
 
 	if !p.expectPeek(types.LPAREN) {
-		return nil
+		return p.badExpression(expression.Token.Start)
 	}
 
 	p.nextToken()
 	expression.Condition = p.parseExpression(LOWEST)
 
 	if !p.expectPeek(types.RPAREN) {
-		return nil
+		return p.badExpression(expression.Token.Start)
 	}
 
 	if !p.expectPeek(types.LBRACE) {
-		return nil
+		return p.badExpression(expression.Token.Start)
 	}
 
 	expression.Consequence = p.parseBlockStatement()
 
-	if p.peekTokenIs(types.ELSE) {
+	for p.peekTokenIs(types.ELSE) {
 		p.nextToken()
 
+		if p.peekTokenIs(types.IF) {
+			branch := &ast.ElseIfBranch{Token: p.curToken}
+			p.nextToken()
+
+			if !p.expectPeek(types.LPAREN) {
+				return p.badExpression(expression.Token.Start)
+			}
+
+			p.nextToken()
+			branch.Condition = p.parseExpression(LOWEST)
+
+			if !p.expectPeek(types.RPAREN) {
+				return p.badExpression(expression.Token.Start)
+			}
+
+			if !p.expectPeek(types.LBRACE) {
+				return p.badExpression(expression.Token.Start)
+			}
+
+			branch.Consequence = p.parseBlockStatement()
+			expression.ElseIfs = append(expression.ElseIfs, branch)
+			continue
+		}
+
 		if !p.expectPeek(types.LBRACE) {
-			return nil
+			return p.badExpression(expression.Token.Start)
 		}
 
 		expression.Alternative = p.parseBlockStatement()
+		break
 	}
 
 	return expression
@@ -460,6 +849,15 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p.curToken}
 	block.Statements = []ast.Statement{}
 
+	p.blockDepth++
+	defer func() { p.blockDepth-- }()
+
+	defer p.leave()
+	if !p.enter() {
+		block.Rbrace = p.curToken
+		return block
+	}
+
 	p.nextToken()
 
 	for !p.curTokenIs(types.RBRACE) && !p.curTokenIs(types.EOF) {
@@ -470,6 +868,8 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		p.nextToken()
 	}
 
+	block.Rbrace = p.curToken
+
 	return block
 }
 
@@ -477,16 +877,20 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	lit := &ast.FunctionLiteral{Token: p.curToken}
 
 	if !p.expectPeek(types.LPAREN) {
-		return nil
+		return p.badExpression(lit.Token.Start)
 	}
 
 	lit.Parameters = p.parseFunctionParameters()
 
 	if !p.expectPeek(types.LBRACE) {
-		return nil
+		return p.badExpression(lit.Token.Start)
 	}
 
+	// break and continue cannot cross a function boundary
+	loopDepth := p.loopDepth
+	p.loopDepth = 0
 	lit.Body = p.parseBlockStatement()
+	p.loopDepth = loopDepth
 
 	return lit
 }
@@ -518,21 +922,10 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	return identifiers
 }
 
-func (p *Parser) parsePrintStatement() ast.Expression {
-	exp := &ast.CallExpression{Token: p.curToken}
-	exp.Function = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-
-	if !p.expectPeek(types.LPAREN) {
-		return nil
-	}
-
-	exp.Arguments = p.parseExpressionList(types.RPAREN)
-	return exp
-}
-
 func (p *Parser) parseCallExpression(fn ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.curToken, Function: fn}
 	exp.Arguments = p.parseExpressionList(types.RPAREN)
+	exp.Rparen = p.curToken
 	return exp
 }
 
@@ -579,13 +972,95 @@ func (p *Parser) expectPeek(t types.TokenType) bool {
 }
 
 func (p *Parser) Errors() []string {
+	msgs := make([]string, len(p.errors))
+	for i, err := range p.errors {
+		msgs[i] = err.Error()
+	}
+	return msgs
+}
+
+func (p *Parser) ParseErrors() []*ParseError {
 	return p.errors
 }
 
+func (p *Parser) errorAt(tok types.Token, format string, a ...interface{}) *ParseError {
+	if p.panicking {
+		return nil
+	}
+	p.panicking = true
+
+	err := &ParseError{
+		Message: fmt.Sprintf(format, a...),
+		Pos:     tok.Start,
+		End:     tok.End,
+		Actual:  tok,
+	}
+	p.errors = append(p.errors, err)
+	return err
+}
+
 func (p *Parser) peekError(t types.TokenType) {
-	msg := fmt.Sprintf("expected next token to be %s, got %s instead",
+	err := p.errorAt(p.peekToken, "expected next token to be %s, got %s instead",
 		t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	if err != nil {
+		err.Expected = []types.TokenType{t}
+	}
+}
+
+// synchronize skips to the end of a statement that started at brace balance
+// depth. It stops before a } only when that brace closes an enclosing block;
+// any other } belongs to the bad statement or is stray and is skipped.
+func (p *Parser) synchronize(depth int) {
+	for !p.curTokenIs(types.EOF) && !p.peekTokenIs(types.EOF) {
+		balance := p.braceDepth
+		switch p.curToken.Type {
+		case types.LBRACE:
+			balance++
+		case types.RBRACE:
+			balance--
+		}
+
+		if p.curTokenIs(types.SEMICOLON) && balance == depth {
+			p.panicking = false
+			return
+		}
+
+		if balance == depth {
+			switch p.peekToken.Type {
+			case types.RBRACE:
+				if p.blockDepth > 0 {
+					p.panicking = false
+					return
+				}
+			case types.LET, types.RETURN, types.WHILE, types.FOR, types.BREAK, types.CONTINUE:
+				p.panicking = false
+				return
+			}
+		}
+
+		p.nextToken()
+	}
+
+	p.panicking = false
+}
+
+// enter reports an error and returns false when the input nests deeper than
+// maxNestingDepth. Every call must be paired with a deferred leave.
+func (p *Parser) enter() bool {
+	p.nesting++
+	if p.nesting > maxNestingDepth {
+		p.errorAt(p.curToken, "nesting exceeds the limit of %d levels", maxNestingDepth)
+		return false
+	}
+	return true
+}
+
+func (p *Parser) leave() {
+	p.nesting--
+}
+
+func (p *Parser) badExpression(from types.Position) ast.Expression {
+	return &ast.BadExpression{From: from, To: p.curToken.End}
 }
 
 func (p *Parser) registerPrefix(tokenType types.TokenType, fn prefixParseFn) {
@@ -597,8 +1072,7 @@ func (p *Parser) registerInfix(tokenType types.TokenType, fn infixParseFn) {
 }
 
 func (p *Parser) noPrefixParseFnError(t types.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.errorAt(p.curToken, "no prefix parse function for %s found", t)
 }
 
 func (p *Parser) peekPrecedence() int {
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/ast"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/lexer"
+	"strings"
+	"testing"
+)
+
+func TestOneTypoOneError(t *testing.T) {
+	tests := []struct {
+		input      string
+		statements int
+	}{
+		{"let x = ;\nlet y = 2;", 2},
+		{"let x 5;\nlet y = 2;", 2},
+		{"let x = {1}\nlet y = 2", 2},
+		{"let f = func(a) { let b = ); return a }; f(1)", 2},
+		{"while (true) { let = 1 }\nlet y = 2", 2},
+		{"if (x) { 1 + } else { 2 }; 3", 2},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+
+		if errs := p.Errors(); len(errs) != 1 {
+			t.Errorf("%q: expected 1 error, got %d: %v", tt.input, len(errs), errs)
+		}
+		if len(program.Statements) != tt.statements {
+			t.Errorf("%q: expected %d statements, got %d", tt.input, tt.statements, len(program.Statements))
+		}
+	}
+}
+
+func TestRecoveryMarksBadNodes(t *testing.T) {
+	p := New(lexer.New("let x = ;\nlet y = 2;"))
+	program := p.ParseProgram()
+
+	if _, ok := program.Statements[0].(*ast.BadStatement); !ok {
+		t.Errorf("expected *ast.BadStatement, got %T", program.Statements[0])
+	}
+	if stmt, ok := program.Statements[1].(*ast.LetStatement); !ok || stmt.Name.Value != "y" {
+		t.Errorf("statement after the error was not recovered: %s", program.Statements[1].String())
+	}
+}
+
+func TestNestingLimit(t *testing.T) {
+	depth := maxNestingDepth + 1
+	inputs := []string{
+		strings.Repeat("(", depth) + "1" + strings.Repeat(")", depth),
+		strings.Repeat("[", depth) + strings.Repeat("]", depth),
+		strings.Repeat("while (true) {", depth) + strings.Repeat("}", depth),
+	}
+
+	for _, input := range inputs {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+
+		errs := p.ParseErrors()
+		if len(errs) != 1 || !strings.Contains(errs[0].Message, "nesting exceeds the limit") {
+			t.Errorf("%.20q...: expected a nesting error, got %v", input, p.Errors())
+		}
+	}
+}
+
+func TestLeadingZeroLiterals(t *testing.T) {
+	for _, input := range []string{"010", "09", "0_1", "01.5"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+
+		errs := p.ParseErrors()
+		if len(errs) != 1 || !strings.Contains(errs[0].Message, "use the 0o prefix") {
+			t.Errorf("%s: expected a leading zero error, got %v", input, p.Errors())
+		}
+	}
+
+	for _, input := range []string{"0", "0.5", "0e3", "0o17"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+
+		if errs := p.Errors(); len(errs) != 0 {
+			t.Errorf("%s: unexpected errors %v", input, errs)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package repl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/lexer"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/parser"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/sho"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/types"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+const (
+	PROMPT       = ">> "
+	CONTINUATION = ".. "
+)
+
+const help = `commands:
+  :tokens <code>  print the tokens of code
+  :ast <code>     print the parsed program of code
+  :env            list the bindings of the current environment
+  :builtins       list the builtin functions
+  :reset          discard all bindings and start over
+  :help           show this message
+  :quit           leave the repl
+`
+
+type Repl struct {
+	in     *bufio.Reader
+	out    io.Writer
+	opts   []sho.Option
+	interp *sho.Interpreter
+
+	// session holds every input so far; each input is lexed at the line it
+	// occupies in it, so errors can point into earlier inputs
+	session strings.Builder
+	line    int
+}
+
+// New returns a repl that evaluates each input with an interpreter built from
+// opts, so limits such as sho.WithTimeout and sho.WithMaxSteps apply to every
+// input. The repl's own input and output replace any given in opts.
+func New(in io.Reader, out io.Writer, opts ...sho.Option) *Repl {
+	r := &Repl{
+		in:   bufio.NewReader(in),
+		out:  out,
+		opts: opts,
+	}
+	r.reset()
+	return r
+}
+
+func (r *Repl) reset() {
+	// scripts read from the same buffer as the repl, so input() and
+	// readLine() consume the lines that follow the statement calling them
+	r.interp = sho.New(append(r.opts,
+		sho.WithStdin(r.in),
+		sho.WithStdout(r.out),
+		sho.WithStderr(r.out),
+	)...)
+	r.session.Reset()
+	r.line = 1
+}
+
+func Start(in io.Reader, out io.Writer, opts ...sho.Option) {
+	New(in, out, opts...).Run()
+}
+
+func (r *Repl) Run() {
+	for {
+		input, ok := r.read()
+		if !ok {
+			return
+		}
+
+		if strings.HasPrefix(strings.TrimSpace(input), ":") {
+			if !r.command(strings.TrimSpace(input)) {
+				return
+			}
+			continue
+		}
+
+		r.eval(input)
+	}
+}
+
+func (r *Repl) read() (string, bool) {
+	var buf strings.Builder
+	prompt := PROMPT
+
+	for {
+		fmt.Fprint(r.out, prompt)
+		// read through the runtime, which finishes any read that an
+		// interrupted input() left pending before starting another
+		line, err := r.interp.Runtime().ReadLine(context.Background())
+		if err != nil {
+			if buf.Len() > 0 {
+				fmt.Fprintln(r.out)
+				return buf.String(), true
+			}
+			return "", false
+		}
+
+		buf.WriteString(line)
+		buf.WriteString("\n")
+
+		if strings.HasPrefix(strings.TrimSpace(buf.String()), ":") || !isIncomplete(buf.String()) {
+			return buf.String(), true
+		}
+		prompt = CONTINUATION
+	}
+}
+
+func (r *Repl) command(input string) bool {
+	name, arg, _ := strings.Cut(input, " ")
+
+	switch name {
+	case ":tokens":
+		l := lexer.New(arg)
+		for tok := l.NextToken(); tok.Type != types.EOF; tok = l.NextToken() {
+			fmt.Fprintf(r.out, "%-7s %-8s %q\n", tok.Start, tok.Type, tok.Literal)
+		}
+	case ":ast":
+		p := parser.New(lexer.New(arg))
+		program := p.ParseProgram()
+		r.printParserErrors(p.ParseErrors(), arg)
+		for _, stmt := range program.Statements {
+			fmt.Fprintf(r.out, "%T %s\n", stmt, stmt.String())
+		}
+	case ":env":
+		env := r.interp.Environment()
+		for _, name := range env.Names() {
+			value, _ := env.Get(name)
+			fmt.Fprintf(r.out, "%s = %s\n", name, value.Inspect())
+		}
+	case ":builtins":
+		fmt.Fprint(r.out, r.interp.Runtime().Builtins().Docs())
+	case ":reset":
+		r.reset()
+	case ":help":
+		fmt.Fprint(r.out, help)
+	case ":quit", ":exit":
+		return false
+	default:
+		fmt.Fprintf(r.out, "unknown command %s, try :help\n", name)
+	}
+
+	return true
+}
+
+func (r *Repl) eval(input string) {
+	p := parser.New(lexer.NewAt(input, r.line))
+	program := p.ParseProgram()
+
+	r.session.WriteString(input)
+	r.line += strings.Count(input, "\n")
+	source := r.session.String()
+
+	if r.printParserErrors(p.ParseErrors(), source) || len(program.Statements) == 0 {
+		return
+	}
+
+	// an interrupt stops the running input instead of the whole repl
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := r.interp.RunProgram(ctx, program)
+	if err != nil {
+		var runtimeErr *sho.RuntimeError
+		if errors.As(err, &runtimeErr) {
+			fmt.Fprintln(r.out, runtimeErr.Err.Traceback("", source))
+		} else {
+			fmt.Fprintln(r.out, err)
+		}
+		return
+	}
+	fmt.Fprintln(r.out, result.Inspect())
+}
+
+func (r *Repl) printParserErrors(errs []*parser.ParseError, source string) bool {
+	for _, err := range errs {
+		fmt.Fprintln(r.out, "syntax error:", err.Render("", source))
+	}
+	return len(errs) != 0
+}
+
+func isIncomplete(input string) bool {
+	depth := 0
+	l := lexer.New(input)
+
+	for tok := l.NextToken(); tok.Type != types.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case types.LPAREN, types.LBRACE, types.LBRACKET:
+			depth++
+		case types.RPAREN, types.RBRACE, types.RBRACKET:
+			depth--
+		}
+	}
+
+	return depth > 0
+}
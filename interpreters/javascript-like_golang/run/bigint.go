@@ -0,0 +1,160 @@
+package run
+
+import (
+	"math"
+	"math/big"
+)
+
+// maxBigIntBits bounds the results of ** and << so that a single expression
+// cannot ask for an unbounded amount of memory before it is tracked.
+const maxBigIntBits = 1 << 26
+
+func evalBigIntInfixExpression(
+	operator string,
+	leftVal, rightVal *big.Int,
+) Object {
+	switch operator {
+	case "+":
+		return normalizeBigInt(new(big.Int).Add(leftVal, rightVal))
+	case "-":
+		return normalizeBigInt(new(big.Int).Sub(leftVal, rightVal))
+	case "*":
+		return normalizeBigInt(new(big.Int).Mul(leftVal, rightVal))
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero")
+		}
+		return normalizeBigInt(new(big.Int).Quo(leftVal, rightVal))
+	case "%":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero")
+		}
+		return normalizeBigInt(new(big.Int).Rem(leftVal, rightVal))
+	case "**":
+		return evalBigIntPower(leftVal, rightVal)
+	case "&":
+		return normalizeBigInt(new(big.Int).And(leftVal, rightVal))
+	case "|":
+		return normalizeBigInt(new(big.Int).Or(leftVal, rightVal))
+	case "^":
+		return normalizeBigInt(new(big.Int).Xor(leftVal, rightVal))
+	case "<<":
+		if rightVal.Sign() < 0 {
+			return newError("negative shift count: %s", rightVal)
+		}
+		if !rightVal.IsInt64() || int64(leftVal.BitLen())+rightVal.Int64() > maxBigIntBits {
+			return newError("shift count too large: %s", rightVal)
+		}
+		return normalizeBigInt(new(big.Int).Lsh(leftVal, uint(rightVal.Int64())))
+	case ">>":
+		if rightVal.Sign() < 0 {
+			return newError("negative shift count: %s", rightVal)
+		}
+		shift := int64(leftVal.BitLen())
+		if rightVal.IsInt64() {
+			shift = min(shift, rightVal.Int64())
+		}
+		return normalizeBigInt(new(big.Int).Rsh(leftVal, uint(shift)))
+	case "<":
+		return nativeBoolToPyMonkeyBoolean(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToPyMonkeyBoolean(leftVal.Cmp(rightVal) > 0)
+	case "<=":
+		return nativeBoolToPyMonkeyBoolean(leftVal.Cmp(rightVal) <= 0)
+	case ">=":
+		return nativeBoolToPyMonkeyBoolean(leftVal.Cmp(rightVal) >= 0)
+	case "==":
+		return nativeBoolToPyMonkeyBoolean(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToPyMonkeyBoolean(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError("unknown operator: %s", operator)
+	}
+}
+
+func evalBigIntPower(base, exponent *big.Int) Object {
+	if exponent.Sign() < 0 {
+		return evalFloatInfixExpression("**", toFloat(&BigInt{Value: base}), toFloat(&BigInt{Value: exponent}))
+	}
+	if base.CmpAbs(big.NewInt(1)) <= 0 {
+		if base.Sign() < 0 && exponent.Bit(0) == 1 {
+			return &Integer{Value: -1}
+		}
+		if base.Sign() == 0 && exponent.Sign() > 0 {
+			return &Integer{Value: 0}
+		}
+		return &Integer{Value: 1}
+	}
+	if !exponent.IsInt64() || exponent.Int64() > maxBigIntBits/int64(base.BitLen()-1) {
+		return newError("exponent too large: %s", exponent)
+	}
+	return normalizeBigInt(new(big.Int).Exp(base, exponent, nil))
+}
+
+func normalizeBigInt(value *big.Int) Object {
+	if value.IsInt64() {
+		return &Integer{Value: value.Int64()}
+	}
+	return &BigInt{Value: value}
+}
+
+func isIntegral(obj Object) bool {
+	switch obj.(type) {
+	case *Integer, *BigInt:
+		return true
+	default:
+		return false
+	}
+}
+
+func toBigInt(obj Object) *big.Int {
+	switch obj := obj.(type) {
+	case *Integer:
+		return big.NewInt(obj.Value)
+	case *BigInt:
+		return obj.Value
+	default:
+		return new(big.Int)
+	}
+}
+
+func addInt64(a, b int64) (int64, bool) {
+	c := a + b
+	return c, (a^c)&(b^c) >= 0
+}
+
+func subInt64(a, b int64) (int64, bool) {
+	c := a - b
+	return c, (a^b)&(a^c) >= 0
+}
+
+func powInt64(base, exponent int64) (int64, bool) {
+	result := int64(1)
+	for exponent > 0 {
+		if exponent&1 == 1 {
+			var ok bool
+			if result, ok = mulInt64(result, base); !ok {
+				return 0, false
+			}
+		}
+		exponent >>= 1
+		if exponent > 0 {
+			var ok bool
+			if base, ok = mulInt64(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+	return result, true
+}
+
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	if (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	c := a * b
+	return c, c/b == a
+}
@@ -0,0 +1,325 @@
+package run
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Param struct {
+	Name     string
+	Types    []ObjectType
+	Optional bool
+	Variadic bool
+}
+
+func (p Param) accepts(obj Object) bool {
+	if len(p.Types) == 0 {
+		return true
+	}
+	for _, t := range p.Types {
+		if obj.Type() == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Param) String() string {
+	var out strings.Builder
+	if p.Variadic {
+		out.WriteString("...")
+	}
+	out.WriteString(p.Name)
+	if p.Optional {
+		out.WriteString("?")
+	}
+	if len(p.Types) > 0 {
+		types := make([]string, len(p.Types))
+		for i, t := range p.Types {
+			types[i] = string(t)
+		}
+		out.WriteString(": " + strings.Join(types, " | "))
+	}
+	return out.String()
+}
+
+func (b *Builtin) Signature() string {
+	params := make([]string, len(b.Params))
+	for i, p := range b.Params {
+		params[i] = p.String()
+	}
+	return fmt.Sprintf("%s(%s)", b.displayName(), strings.Join(params, ", "))
+}
+
+func (b *Builtin) checkArgs(args []Object) *Error {
+	if b.Params == nil {
+		return nil
+	}
+
+	required, max := 0, len(b.Params)
+	for _, p := range b.Params {
+		if p.Variadic {
+			max = -1
+		} else if !p.Optional {
+			required++
+		}
+	}
+
+	if len(args) < required || (max >= 0 && len(args) > max) {
+		want := fmt.Sprint(required)
+		switch {
+		case max < 0:
+			want = fmt.Sprintf("at least %d", required)
+		case max != required:
+			want = fmt.Sprintf("%d to %d", required, max)
+		}
+		return newError("wrong number of arguments to `%s`. got=%d, want=%s", b.displayName(), len(args), want)
+	}
+
+	for i, arg := range args {
+		p := b.Params[min(i, len(b.Params)-1)]
+		if !p.accepts(arg) {
+			types := make([]string, len(p.Types))
+			for j, t := range p.Types {
+				types[j] = string(t)
+			}
+			return newError("argument `%s` to `%s` must be %s, got %s",
+				p.Name, b.displayName(), strings.Join(types, " or "), arg.Type())
+		}
+	}
+
+	return nil
+}
+
+func (b *Builtin) displayName() string {
+	if b.Name == "" {
+		return "builtin"
+	}
+	return b.Name
+}
+
+type Builtins struct {
+	fns map[string]*Builtin
+}
+
+func NewBuiltins() *Builtins {
+	return &Builtins{fns: make(map[string]*Builtin)}
+}
+
+func DefaultBuiltins(rt *Runtime) *Builtins {
+	b := NewBuiltins()
+	// each runtime gets its own copies, so a host changing one of them does
+	// not affect other interpreters
+	for name, builtin := range defaultBuiltins {
+		copied := *builtin
+		copied.Params = append([]Param(nil), builtin.Params...)
+		b.Register(name, &copied)
+	}
+	for name, builtin := range ioBuiltins(rt) {
+		b.Register(name, builtin)
+	}
+	return b
+}
+
+func (b *Builtins) Register(name string, builtin *Builtin) {
+	b.fns[name] = builtin
+}
+
+func (b *Builtins) Remove(name string) {
+	delete(b.fns, name)
+}
+
+func (b *Builtins) Lookup(name string) (*Builtin, bool) {
+	builtin, ok := b.fns[name]
+	return builtin, ok
+}
+
+func (b *Builtins) Names() []string {
+	names := make([]string, 0, len(b.fns))
+	for name := range b.fns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (b *Builtins) Docs() string {
+	var out strings.Builder
+	for _, name := range b.Names() {
+		builtin := b.fns[name]
+		out.WriteString(builtin.Signature())
+		out.WriteString("\n")
+		if builtin.Description != "" {
+			out.WriteString("    " + builtin.Description + "\n")
+		}
+	}
+	return out.String()
+}
+
+var defaultBuiltins = map[string]*Builtin{
+	"len": {
+		Name:        "len",
+		Description: "Returns the number of elements of an array or bytes of a string.",
+		Params: []Param{
+			{Name: "value", Types: []ObjectType{ARRAY_OBJ, STRING_OBJ}},
+		},
+		Fn: func(args ...Object) Object {
+			switch arg := args[0].(type) {
+			case *Array:
+				return &Integer{Value: int64(len(arg.Elements))}
+			case *String:
+				return &Integer{Value: int64(len(arg.Value))}
+			default:
+				return newError("argument to `len` not supported, got %s", arg.Type())
+			}
+		},
+	},
+	"int": {
+		Name:        "int",
+		Description: "Converts a number, decimal string or boolean to an integer, truncating floats toward zero.",
+		Params: []Param{
+			{Name: "value", Types: []ObjectType{INTEGER_OBJ, BIGINT_OBJ, FLOAT_OBJ, STRING_OBJ, BOOLEAN_OBJ}},
+		},
+		Fn: func(args ...Object) Object {
+			switch arg := args[0].(type) {
+			case *Integer, *BigInt:
+				return arg
+			case *Float:
+				if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) {
+					return newError("cannot convert %s to INTEGER", arg.Inspect())
+				}
+				value, _ := big.NewFloat(arg.Value).Int(nil)
+				return normalizeBigInt(value)
+			case *String:
+				value, ok := new(big.Int).SetString(strings.TrimSpace(arg.Value), 10)
+				if !ok {
+					return newError("cannot convert %q to INTEGER", arg.Value)
+				}
+				return normalizeBigInt(value)
+			case *Boolean:
+				if arg.Value {
+					return &Integer{Value: 1}
+				}
+				return &Integer{Value: 0}
+			default:
+				return newError("argument to `int` not supported, got %s", arg.Type())
+			}
+		},
+	},
+	"float": {
+		Name:        "float",
+		Description: "Converts a number or string to a float.",
+		Params: []Param{
+			{Name: "value", Types: []ObjectType{INTEGER_OBJ, BIGINT_OBJ, FLOAT_OBJ, STRING_OBJ}},
+		},
+		Fn: func(args ...Object) Object {
+			switch arg := args[0].(type) {
+			case *Integer, *BigInt:
+				return &Float{Value: toFloat(arg)}
+			case *Float:
+				return arg
+			case *String:
+				value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+				if err != nil {
+					return newError("cannot convert %q to FLOAT", arg.Value)
+				}
+				return &Float{Value: value}
+			default:
+				return newError("argument to `float` not supported, got %s", arg.Type())
+			}
+		},
+	},
+	"str": {
+		Name:        "str",
+		Description: "Returns the printed representation of value.",
+		Params: []Param{
+			{Name: "value"},
+		},
+		Fn: func(args ...Object) Object {
+			if s, ok := args[0].(*String); ok {
+				return s
+			}
+			return &String{Value: args[0].Inspect()}
+		},
+	},
+	"push": {
+		Name:        "push",
+		Description: "Returns a copy of array with value appended.",
+		Params: []Param{
+			{Name: "array", Types: []ObjectType{ARRAY_OBJ}},
+			{Name: "value"},
+		},
+		Fn: func(args ...Object) Object {
+			arr := args[0].(*Array)
+			length := len(arr.Elements)
+
+			newElements := make([]Object, length+1, length+1)
+			copy(newElements, arr.Elements)
+			newElements[length] = args[1]
+
+			return &Array{Elements: newElements}
+		},
+	},
+}
+
+func ioBuiltins(rt *Runtime) map[string]*Builtin {
+	return map[string]*Builtin{
+		"print": {
+			Name:        "print",
+			Description: "Writes each value on its own line to standard output.",
+			Params: []Param{
+				{Name: "values", Variadic: true},
+			},
+			Fn: func(args ...Object) Object {
+				return writeLines(rt.Stdout, args)
+			},
+		},
+		"eprint": {
+			Name:        "eprint",
+			Description: "Writes each value on its own line to standard error.",
+			Params: []Param{
+				{Name: "values", Variadic: true},
+			},
+			Fn: func(args ...Object) Object {
+				return writeLines(rt.Stderr, args)
+			},
+		},
+		"input": {
+			Name:        "input",
+			Description: "Writes prompt to standard output and reads a line from standard input, or returns null at end of input.",
+			Params: []Param{
+				{Name: "prompt", Types: []ObjectType{STRING_OBJ}, Optional: true},
+			},
+			Fn: func(args ...Object) Object {
+				if len(args) == 1 {
+					if _, err := io.WriteString(rt.Stdout, args[0].(*String).Value); err != nil {
+						return newError("cannot write prompt: %s", err)
+					}
+				}
+				return rt.readLine()
+			},
+		},
+		"readLine": {
+			Name:        "readLine",
+			Description: "Reads a line from standard input, or returns null at end of input.",
+			Params:      []Param{},
+			Fn: func(args ...Object) Object {
+				return rt.readLine()
+			},
+		},
+	}
+}
+
+func writeLines(w io.Writer, args []Object) Object {
+	for _, arg := range args {
+		if _, err := fmt.Fprintln(w, arg.Inspect()); err != nil {
+			return newError("cannot write output: %s", err)
+		}
+	}
+	return NULL
+}
@@ -0,0 +1,29 @@
+package run
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIntParsesDecimalStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`int("010")`, 10},
+		{`int("-42")`, -42},
+		{`int("+7")`, 7},
+		{`int(" 12 ")`, 12},
+	}
+
+	for _, tt := range tests {
+		result := testEval(t, context.Background(), NewRuntime(), tt.input)
+		if i, ok := result.(*Integer); !ok || i.Value != tt.expected {
+			t.Errorf("%s: got %s, want %d", tt.input, result.Inspect(), tt.expected)
+		}
+	}
+
+	for _, input := range []string{`int("0x1f")`, `int("1_000")`, `int("0b1")`, `int("1.5")`} {
+		expectError(t, testEval(t, context.Background(), NewRuntime(), input), RUNTIME_ERR, "cannot convert")
+	}
+}
@@ -1,87 +1,190 @@
 package run
 
 import (
+	"context"
 	"fmt"
-	"main/ast"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/ast"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/types"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
 )
 
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store   map[string]binding
+	outer   *Environment
+	runtime *Runtime
+}
+
+// binding records whether a name was introduced by let or a parameter, which
+// is what makes a second let for it in the same scope an error.
+type binding struct {
+	value    Object
+	declared bool
 }
 
 func NewEnvironment() *Environment {
-	s := make(map[string]Object)
-	return &Environment{store: s, outer: nil}
+	return NewEnvironmentWithRuntime(NewRuntime())
+}
+
+func NewEnvironmentWithRuntime(rt *Runtime) *Environment {
+	s := make(map[string]binding)
+	return &Environment{store: s, outer: nil, runtime: rt}
 }
 
 func NewEnclosedEnvironment(outer *Environment) *Environment {
-	env := NewEnvironment()
+	env := NewEnvironmentWithRuntime(outer.runtime)
 	env.outer = outer
 	return env
 }
 
+func (e *Environment) Runtime() *Runtime {
+	return e.runtime
+}
+
 func (e *Environment) Get(name string) (Object, bool) {
-	value, ok := e.store[name]
+	b, ok := e.store[name]
 	if !ok && e.outer != nil {
-		value, ok = e.outer.Get(name)
+		return e.outer.Get(name)
 	}
-	return value, ok
+	return b.value, ok
 }
 
+// Set binds name in e without counting as a declaration, so a script may
+// still let the name. It is meant for the host and for internal bindings.
 func (e *Environment) Set(name string, val Object) Object {
-	e.store[name] = val
+	e.store[name] = binding{value: val}
 	return val
 }
 
-var builtins = map[string]*Builtin{
-	"print": {
-		Fn: func(args ...Object) Object {
-			for _, arg := range args {
-				fmt.Println(arg.Inspect())
-			}
-			return NULL
-		},
-	},
-	"len": {
-		Fn: func(args ...Object) Object {
-			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, want=1", len(args))
-			}
+// Declare binds name in e itself, reporting false when a let or parameter in
+// e already declared it.
+func (e *Environment) Declare(name string, val Object) (Object, bool) {
+	if e.store[name].declared {
+		return nil, false
+	}
+	return e.define(name, val), true
+}
 
-			switch arg := args[0].(type) {
-			case *Array:
-				return &Integer{Value: int64(len(arg.Elements))}
-			case *String:
-				return &Integer{Value: int64(len(arg.Value))}
-			default:
-				return newError("argument to `len` not supported, got %T", arg)
-			}
-		},
-	},
-	"push": {
-		Fn: func(args ...Object) Object {
-			if len(args) != 2 {
-				return newError("wrong number of arguments. got=%d, want=2", len(args))
-			}
+func (e *Environment) define(name string, val Object) Object {
+	e.store[name] = binding{value: val, declared: true}
+	return val
+}
+
+// ResetDeclarations lets the next program evaluated in e declare its names
+// again. The REPL and sho.Interpreter call it before every input, so that a
+// top-level let can be re-entered, while a name declared twice within one
+// input is still an error.
+func (e *Environment) ResetDeclarations() {
+	for name, b := range e.store {
+		b.declared = false
+		e.store[name] = b
+	}
+}
+
+// Assign updates name in the innermost scope that defines it, reporting false
+// when no enclosing scope does.
+func (e *Environment) Assign(name string, val Object) (Object, bool) {
+	for scope := e; scope != nil; scope = scope.outer {
+		if b, ok := scope.store[name]; ok {
+			b.value = val
+			scope.store[name] = b
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func EvalContext(ctx context.Context, node ast.Node, env *Environment) Object {
+	if env == nil {
+		return Eval(node, env)
+	}
+
+	rt := env.runtime
+	if rt.depth == 0 {
+		rt.steps = 0
+		rt.allocated = 0
+	}
+
+	// a program that never reaches a step would otherwise ignore a context
+	// that is already done
+	if ctx != nil && ctx.Err() != nil {
+		return contextError(ctx.Err())
+	}
+
+	prev := rt.ctx
+	rt.ctx = ctx
+	defer func() { rt.ctx = prev }()
+
+	return Eval(node, env)
+}
+
+func Eval(node ast.Node, env *Environment) (result Object) {
+	if isNilNode(node) {
+		return newError("cannot evaluate missing node")
+	}
+	if env == nil {
+		return newError("cannot evaluate without an environment")
+	}
+
+	rt := env.runtime
+	if rt.depth >= maxEvalDepth {
+		return newError("maximum evaluation depth exceeded")
+	}
+
+	frames := len(rt.frames)
+	rt.depth++
 
-			if args[0].Type() != ARRAY_OBJ {
-				return newError("argument to `push` must be ARRAY, got %T", args[0])
+	defer func() {
+		rt.depth--
+
+		if r := recover(); r != nil {
+			rt.frames = rt.frames[:frames]
+			result = newError("internal error: %v", r)
+		}
+
+		if err, ok := result.(*Error); ok {
+			if !err.Pos.IsValid() {
+				err.Pos, err.End = nodeSpan(node)
+			}
+			if err.Stack == nil {
+				err.Stack = rt.StackTrace()
 			}
+		}
+	}()
 
-			arr := args[0].(*Array)
-			length := len(arr.Elements)
+	return evalNode(node, env)
+}
 
-			newElements := make([]Object, length+1, length+1)
-			copy(newElements, arr.Elements)
-			newElements[length] = args[1]
+func nodeSpan(node ast.Node) (start, end types.Position) {
+	defer func() {
+		if recover() != nil {
+			start, end = types.Position{}, types.Position{}
+		}
+	}()
+	return node.Pos(), node.End()
+}
 
-			return &Array{Elements: newElements}
-		},
-	},
+func isNilNode(node ast.Node) bool {
+	if node == nil {
+		return true
+	}
+	v := reflect.ValueOf(node)
+	return v.Kind() == reflect.Ptr && v.IsNil()
 }
 
-func Eval(node ast.Node, env *Environment) Object {
+func evalNode(node ast.Node, env *Environment) Object {
 	switch node := node.(type) {
 
 	case *ast.Program:
@@ -105,15 +208,50 @@ func Eval(node ast.Node, env *Environment) Object {
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		if fn, ok := val.(*Function); ok && fn.Name == "" {
+			fn.Name = node.Name.Value
+		}
+		if _, ok := env.Declare(node.Name.Value, val); !ok {
+			return newErrorAt(node.Name, "%s has already been declared", node.Name.Value)
+		}
 		return NULL
 
 	case *ast.ClassStatement:
 		return evalClassStatement(node, env)
 
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+
+	case *ast.ForOfStatement:
+		return evalForOfStatement(node, env)
+
+	case *ast.ForInStatement:
+		return evalForInStatement(node, env)
+
+	case *ast.BreakStatement:
+		return BREAK
+
+	case *ast.ContinueStatement:
+		return CONTINUE
+
+	case *ast.BadStatement:
+		return newError("cannot evaluate malformed statement")
+
+	case *ast.BadExpression:
+		return newError("cannot evaluate malformed expression")
+
 	case *ast.IntegerLiteral:
 		return &Integer{Value: node.Value}
 
+	case *ast.BigIntegerLiteral:
+		return &BigInt{Value: new(big.Int).Set(node.Value)}
+
+	case *ast.FloatLiteral:
+		return &Float{Value: node.Value}
+
 	case *ast.StringLiteral:
 		return &String{Value: node.Value}
 
@@ -122,6 +260,9 @@ func Eval(node ast.Node, env *Environment) Object {
 		if len(elements) == 1 && isError(elements[0]) {
 			return elements[0]
 		}
+		if err := env.runtime.checkCollectionSize(len(elements)); err != nil {
+			return err
+		}
 		return &Array{Elements: elements}
 
 	case *ast.ObjectLiteral:
@@ -148,6 +289,9 @@ func Eval(node ast.Node, env *Environment) Object {
 	case *ast.AssignmentExpression:
 		return evalAssignmentExpression(node, env)
 
+	case *ast.UpdateExpression:
+		return evalUpdateExpression(node, env)
+
 	case *ast.NewExpression:
 		class := Eval(node.Class, env)
 		if isError(class) {
@@ -157,7 +301,7 @@ func Eval(node ast.Node, env *Environment) Object {
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return evalNewExpression(class, args)
+		return evalNewExpression(class, args, node.Pos(), env)
 
 	case *ast.ThisExpression:
 		return evalThisExpression(env)
@@ -184,12 +328,16 @@ func Eval(node ast.Node, env *Environment) Object {
 			return left
 		}
 
+		if isLogicalOperator(node.Operator) {
+			return evalLogicalExpression(node.Operator, left, node.Right, env)
+		}
+
 		right := Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
 
-		return evalInfixExpression(node.Operator, left, right)
+		return evalInfixExpression(node.Operator, left, right, env)
 
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
@@ -213,7 +361,7 @@ func Eval(node ast.Node, env *Environment) Object {
 			return args[0]
 		}
 
-		return applyFunction(function, args)
+		return applyFunction(function, args, node.Pos(), env)
 
 	}
 
@@ -241,11 +389,15 @@ func evalBlockStatement(block *ast.BlockStatement, env *Environment) Object {
 	var result Object
 
 	for _, statement := range block.Statements {
+		if err := env.runtime.step(); err != nil {
+			return err
+		}
+
 		result = Eval(statement, env)
 
 		if result != nil {
 			rt := result.Type()
-			if rt == RETURN_VALUE_OBJ || rt == ERROR_OBJ {
+			if rt == RETURN_VALUE_OBJ || rt == ERROR_OBJ || rt == BREAK_OBJ || rt == CONTINUE_OBJ {
 				return result
 			}
 		}
@@ -254,6 +406,165 @@ func evalBlockStatement(block *ast.BlockStatement, env *Environment) Object {
 	return result
 }
 
+// Loop bodies run in a fresh scope per iteration. Assignments inside them go
+// through Environment.Assign, which updates the binding where it was declared
+// rather than creating one in the iteration scope.
+func evalWhileStatement(node *ast.WhileStatement, env *Environment) Object {
+	for {
+		if err := env.runtime.step(); err != nil {
+			return err
+		}
+
+		condition := Eval(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return NULL
+		}
+
+		result := Eval(node.Body, NewEnclosedEnvironment(env))
+		if result, done := loopControl(result); done {
+			return result
+		}
+	}
+}
+
+func evalForStatement(node *ast.ForStatement, env *Environment) Object {
+	loopEnv := NewEnclosedEnvironment(env)
+
+	if node.Init != nil {
+		if init := Eval(node.Init, loopEnv); isError(init) {
+			return init
+		}
+	}
+
+	for {
+		if err := env.runtime.step(); err != nil {
+			return err
+		}
+
+		if node.Condition != nil {
+			condition := Eval(node.Condition, loopEnv)
+			if isError(condition) {
+				return condition
+			}
+			if !isTruthy(condition) {
+				return NULL
+			}
+		}
+
+		result := Eval(node.Body, NewEnclosedEnvironment(loopEnv))
+		if result, done := loopControl(result); done {
+			return result
+		}
+
+		if node.Update != nil {
+			if update := Eval(node.Update, loopEnv); isError(update) {
+				return update
+			}
+		}
+	}
+}
+
+func evalForOfStatement(node *ast.ForOfStatement, env *Environment) Object {
+	iterable := Eval(node.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+
+	var values []Object
+	switch iterable := iterable.(type) {
+	case *Array:
+		values = iterable.Elements
+	case *String:
+		for _, r := range iterable.Value {
+			values = append(values, &String{Value: string(r)})
+		}
+	case *Hash:
+		return newErrorAt(node.Iterable, "cannot iterate over HASH with for-of, use for-in for its keys")
+	default:
+		return newErrorAt(node.Iterable, "cannot iterate over %s with for-of", iterable.Type())
+	}
+
+	return evalForEach(node.Name.Value, values, node.Body, env)
+}
+
+func evalForInStatement(node *ast.ForInStatement, env *Environment) Object {
+	object := Eval(node.Object, env)
+	if isError(object) {
+		return object
+	}
+
+	hash, ok := object.(*Hash)
+	if !ok {
+		return newErrorAt(node.Object, "cannot iterate over %s with for-in", object.Type())
+	}
+
+	return evalForEach(node.Name.Value, sortedHashKeys(hash), node.Body, env)
+}
+
+func evalForEach(name string, values []Object, body *ast.BlockStatement, env *Environment) Object {
+	for _, value := range values {
+		if err := env.runtime.step(); err != nil {
+			return err
+		}
+
+		iterEnv := NewEnclosedEnvironment(env)
+		iterEnv.Set(name, value)
+
+		result := Eval(body, NewEnclosedEnvironment(iterEnv))
+		if result, done := loopControl(result); done {
+			return result
+		}
+	}
+
+	return NULL
+}
+
+// sortedHashKeys orders numeric keys by value ahead of all other keys, which
+// are ordered by type and then by their printed form.
+func sortedHashKeys(hash *Hash) []Object {
+	keys := make([]Object, 0, len(hash.Pairs))
+	for _, pair := range hash.Pairs {
+		keys = append(keys, pair.Key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		switch {
+		case isIntegral(a) && isIntegral(b):
+			return toBigInt(a).Cmp(toBigInt(b)) < 0
+		case isNumeric(a) && isNumeric(b):
+			return toFloat(a) < toFloat(b)
+		case isNumeric(a) != isNumeric(b):
+			return isNumeric(a)
+		case a.Type() != b.Type():
+			return a.Type() < b.Type()
+		default:
+			return a.Inspect() < b.Inspect()
+		}
+	})
+
+	return keys
+}
+
+// loopControl reports whether the loop that produced result has to stop, and
+// what it evaluates to in that case.
+func loopControl(result Object) (Object, bool) {
+	if result == nil {
+		return nil, false
+	}
+	switch result.Type() {
+	case BREAK_OBJ:
+		return NULL, true
+	case RETURN_VALUE_OBJ, ERROR_OBJ:
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
 func evalClassStatement(node *ast.ClassStatement, env *Environment) Object {
 	class := &Class{
 		Name:    node.Name.Value,
@@ -284,6 +595,7 @@ func evalClassStatement(node *ast.ClassStatement, env *Environment) Object {
 		}
 
 		class.Methods[methodName] = &Function{
+			Name:       node.Name.Value + "." + methodName,
 			Parameters: parameters,
 			Body:       method.Body,
 			Env:        env,
@@ -302,6 +614,9 @@ func evalObjectLiteral(node *ast.ObjectLiteral, env *Environment) Object {
 		if isError(key) {
 			return key
 		}
+		if _, ok := key.(Hashable); !ok {
+			return newError("unusable as hash key: %s", key.Type())
+		}
 
 		value := Eval(valueNode, env)
 		if isError(value) {
@@ -311,6 +626,9 @@ func evalObjectLiteral(node *ast.ObjectLiteral, env *Environment) Object {
 		pairs[key.Inspect()] = HashPair{Key: key, Value: value}
 	}
 
+	if err := env.runtime.checkCollectionSize(len(pairs)); err != nil {
+		return err
+	}
 	return &Hash{Pairs: pairs}
 }
 
@@ -339,6 +657,9 @@ func evalArrayIndexExpression(array, index Object) Object {
 
 func evalHashIndexExpression(hash, index Object) Object {
 	hashObject := hash.(*Hash)
+	if _, ok := index.(Hashable); !ok {
+		return newError("unusable as hash key: %s", index.Type())
+	}
 	pair, ok := hashObject.Pairs[index.Inspect()]
 	if !ok {
 		return NULL
@@ -370,35 +691,172 @@ func evalPropertyExpression(object Object, property string) Object {
 }
 
 func evalAssignmentExpression(node *ast.AssignmentExpression, env *Environment) Object {
-	value := Eval(node.Value, env)
+	get, set, err := evalAssignmentTarget(node.Left, env)
+	if err != nil {
+		return err
+	}
+
+	var value Object
+	switch operator := strings.TrimSuffix(node.Operator, "="); {
+	case node.Operator == "=":
+		value = Eval(node.Value, env)
+	case isLogicalOperator(operator):
+		current := get()
+		if isError(current) {
+			return current
+		}
+		// a short-circuited &&=, ||= or ??= leaves the target untouched
+		if shortCircuits(operator, current) {
+			return current
+		}
+		value = Eval(node.Value, env)
+	default:
+		current := get()
+		if isError(current) {
+			return current
+		}
+		right := Eval(node.Value, env)
+		if isError(right) {
+			return right
+		}
+		value = evalInfixExpression(operator, current, right, env)
+	}
 	if isError(value) {
 		return value
 	}
 
-	switch left := node.Left.(type) {
+	if fn, ok := value.(*Function); ok && fn.Name == "" {
+		if ident, ok := node.Left.(*ast.Identifier); ok {
+			fn.Name = ident.Value
+		}
+	}
+
+	return set(value)
+}
+
+func evalUpdateExpression(node *ast.UpdateExpression, env *Environment) Object {
+	get, set, err := evalAssignmentTarget(node.Target, env)
+	if err != nil {
+		return err
+	}
+
+	current := get()
+	if isError(current) {
+		return current
+	}
+	if !isNumeric(current) {
+		return newError("unknown operator: %s%s", node.Operator, current.Type())
+	}
+
+	operator := node.Operator[:1]
+	updated := evalInfixExpression(operator, current, &Integer{Value: 1}, env)
+	if isError(updated) {
+		return updated
+	}
+
+	if result := set(updated); isError(result) {
+		return result
+	}
+
+	if node.Prefix {
+		return updated
+	}
+	return current
+}
+
+// evalAssignmentTarget evaluates the object and index parts of an assignment
+// target once, returning functions that read and write the resolved slot.
+func evalAssignmentTarget(target ast.Expression, env *Environment) (get func() Object, set func(Object) Object, err Object) {
+	switch target := target.(type) {
 	case *ast.Identifier:
-		env.Set(left.Value, value)
-		return value
+		get = func() Object { return evalIdentifier(target, env) }
+		set = func(value Object) Object {
+			if _, ok := env.Assign(target.Value, value); !ok {
+				return newError("assignment to undeclared variable %s", target.Value)
+			}
+			return value
+		}
+		return get, set, nil
+
+	case *ast.IndexExpression:
+		object := Eval(target.Left, env)
+		if isError(object) {
+			return nil, nil, object
+		}
+		index := Eval(target.Index, env)
+		if isError(index) {
+			return nil, nil, index
+		}
+		get = func() Object { return evalIndexExpression(object, index) }
+		set = func(value Object) Object { return assignIndex(object, index, value, env) }
+		return get, set, nil
+
 	case *ast.PropertyExpression:
-		object := Eval(left.Object, env)
+		object := Eval(target.Object, env)
 		if isError(object) {
-			return object
+			return nil, nil, object
 		}
+		name := target.Property.Value
+		get = func() Object { return evalPropertyExpression(object, name) }
+		set = func(value Object) Object { return assignProperty(object, name, value, env) }
+		return get, set, nil
 
-		instance, ok := object.(*Instance)
+	default:
+		return nil, nil, newError("invalid left-hand side of assignment: %T", target)
+	}
+}
+
+func assignIndex(object, index, value Object, env *Environment) Object {
+	switch object := object.(type) {
+	case *Array:
+		idx, ok := index.(*Integer)
 		if !ok {
-			return newError("cannot assign to property of non-instance: %T", object)
+			return newError("array index must be INTEGER, got %s", index.Type())
+		}
+		if idx.Value < 0 || idx.Value >= int64(len(object.Elements)) {
+			return newError("index out of range: %d with length %d", idx.Value, len(object.Elements))
 		}
+		object.Elements[idx.Value] = value
+		return value
+	case *Hash:
+		return assignHashPair(object, index, value, env)
+	default:
+		return newError("index assignment not supported: %s", object.Type())
+	}
+}
 
-		propertyName := left.Property.Value
-		instance.Properties[propertyName] = value
+func assignProperty(object Object, name string, value Object, env *Environment) Object {
+	switch object := object.(type) {
+	case *Instance:
+		object.Properties[name] = value
 		return value
+	case *Hash:
+		return assignHashPair(object, &String{Value: name}, value, env)
 	default:
-		return newError("invalid left-hand side of assignment: %T", node.Left)
+		return newError("cannot assign to property of %s", object.Type())
 	}
 }
 
-func evalNewExpression(class Object, args []Object) Object {
+func assignHashPair(hash *Hash, key, value Object, env *Environment) Object {
+	if _, ok := key.(Hashable); !ok {
+		return newError("unusable as hash key: %s", key.Type())
+	}
+
+	hashKey := key.Inspect()
+	if _, ok := hash.Pairs[hashKey]; !ok {
+		if err := env.runtime.checkCollectionSize(len(hash.Pairs) + 1); err != nil {
+			return err
+		}
+		if err := env.runtime.allocate(hashPairSize); err != nil {
+			return err
+		}
+	}
+
+	hash.Pairs[hashKey] = HashPair{Key: key, Value: value}
+	return value
+}
+
+func evalNewExpression(class Object, args []Object, site types.Position, env *Environment) Object {
 	if class.Type() != CLASS_OBJ {
 		return newError("not a class: %T", class)
 	}
@@ -411,6 +869,7 @@ func evalNewExpression(class Object, args []Object) Object {
 
 	for methodName, method := range classObj.Methods {
 		boundMethod := &Function{
+			Name:       method.Name,
 			Parameters: method.Parameters,
 			Body:       method.Body,
 			Env:        method.Env,
@@ -426,7 +885,9 @@ func evalNewExpression(class Object, args []Object) Object {
 			extendedEnv.Set("super", classObj.SuperClass)
 		}
 
-		result := Eval(constructor.Body, extendedEnv)
+		result := env.runtime.call(constructor.Name, site, func() Object {
+			return Eval(constructor.Body, extendedEnv)
+		})
 		if isError(result) {
 			return result
 		}
@@ -462,6 +923,8 @@ func evalPrefixExpression(operator string, right Object) Object {
 		return evalBangOperatorExpression(right)
 	case "-":
 		return evalMinusPrefixOperatorExpression(right)
+	case "~":
+		return evalTildePrefixOperatorExpression(right)
 	default:
 		return newError("unknown operator: %s%s", operator, right.Type())
 	}
@@ -470,12 +933,17 @@ func evalPrefixExpression(operator string, right Object) Object {
 func evalInfixExpression(
 	operator string,
 	left, right Object,
+	env *Environment,
 ) Object {
 	switch {
 	case left.Type() == INTEGER_OBJ && right.Type() == INTEGER_OBJ:
-		return evalIntegerInfixExpression(operator, left, right)
+		return env.runtime.track(evalIntegerInfixExpression(operator, left, right), left, right)
+	case isIntegral(left) && isIntegral(right):
+		return env.runtime.track(evalBigIntInfixExpression(operator, toBigInt(left), toBigInt(right)), left, right)
+	case isNumeric(left) && isNumeric(right):
+		return evalFloatInfixExpression(operator, toFloat(left), toFloat(right))
 	case left.Type() == STRING_OBJ && right.Type() == STRING_OBJ:
-		return evalStringInfixExpression(operator, left, right)
+		return evalStringInfixExpression(operator, left, right, env)
 	case operator == "==":
 		return nativeBoolToPyMonkeyBoolean(left == right)
 	case operator == "!=":
@@ -489,6 +957,37 @@ func evalInfixExpression(
 	}
 }
 
+func isLogicalOperator(operator string) bool {
+	return operator == "&&" || operator == "||" || operator == "??"
+}
+
+// evalLogicalExpression only evaluates the right operand when the left one
+// does not already decide the result, and returns the deciding operand as is.
+func evalLogicalExpression(
+	operator string,
+	left Object,
+	right ast.Expression,
+	env *Environment,
+) Object {
+	if shortCircuits(operator, left) {
+		return left
+	}
+	return Eval(right, env)
+}
+
+func shortCircuits(operator string, left Object) bool {
+	switch operator {
+	case "&&":
+		return !isTruthy(left)
+	case "||":
+		return isTruthy(left)
+	case "??":
+		return left != NULL
+	default:
+		return false
+	}
+}
+
 func evalIntegerInfixExpression(
 	operator string,
 	left, right Object,
@@ -498,17 +997,108 @@ func evalIntegerInfixExpression(
 
 	switch operator {
 	case "+":
-		return &Integer{Value: leftVal + rightVal}
+		if sum, ok := addInt64(leftVal, rightVal); ok {
+			return &Integer{Value: sum}
+		}
+		return evalBigIntInfixExpression(operator, big.NewInt(leftVal), big.NewInt(rightVal))
 	case "-":
-		return &Integer{Value: leftVal - rightVal}
+		if diff, ok := subInt64(leftVal, rightVal); ok {
+			return &Integer{Value: diff}
+		}
+		return evalBigIntInfixExpression(operator, big.NewInt(leftVal), big.NewInt(rightVal))
 	case "*":
-		return &Integer{Value: leftVal * rightVal}
+		if product, ok := mulInt64(leftVal, rightVal); ok {
+			return &Integer{Value: product}
+		}
+		return evalBigIntInfixExpression(operator, big.NewInt(leftVal), big.NewInt(rightVal))
 	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		if leftVal == math.MinInt64 && rightVal == -1 {
+			return evalBigIntInfixExpression(operator, big.NewInt(leftVal), big.NewInt(rightVal))
+		}
 		return &Integer{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &Integer{Value: leftVal % rightVal}
+	case "**":
+		if rightVal < 0 {
+			return &Float{Value: math.Pow(float64(leftVal), float64(rightVal))}
+		}
+		if power, ok := powInt64(leftVal, rightVal); ok {
+			return &Integer{Value: power}
+		}
+		return evalBigIntInfixExpression(operator, big.NewInt(leftVal), big.NewInt(rightVal))
+	case "&":
+		return &Integer{Value: leftVal & rightVal}
+	case "|":
+		return &Integer{Value: leftVal | rightVal}
+	case "^":
+		return &Integer{Value: leftVal ^ rightVal}
+	case "<<":
+		if rightVal < 0 {
+			return newError("negative shift count: %d", rightVal)
+		}
+		if rightVal < 63 && (leftVal<<rightVal)>>rightVal == leftVal {
+			return &Integer{Value: leftVal << rightVal}
+		}
+		return evalBigIntInfixExpression(operator, big.NewInt(leftVal), big.NewInt(rightVal))
+	case ">>":
+		if rightVal < 0 {
+			return newError("negative shift count: %d", rightVal)
+		}
+		return &Integer{Value: leftVal >> min(rightVal, 63)}
+	case "<":
+		return nativeBoolToPyMonkeyBoolean(leftVal < rightVal)
+	case ">":
+		return nativeBoolToPyMonkeyBoolean(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToPyMonkeyBoolean(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToPyMonkeyBoolean(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToPyMonkeyBoolean(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToPyMonkeyBoolean(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s", operator)
+	}
+}
+
+func evalFloatInfixExpression(
+	operator string,
+	leftVal, rightVal float64,
+) Object {
+	switch operator {
+	case "+":
+		return &Float{Value: leftVal + rightVal}
+	case "-":
+		return &Float{Value: leftVal - rightVal}
+	case "*":
+		return &Float{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &Float{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &Float{Value: math.Mod(leftVal, rightVal)}
+	case "**":
+		return &Float{Value: math.Pow(leftVal, rightVal)}
 	case "<":
 		return nativeBoolToPyMonkeyBoolean(leftVal < rightVal)
 	case ">":
 		return nativeBoolToPyMonkeyBoolean(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToPyMonkeyBoolean(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToPyMonkeyBoolean(leftVal >= rightVal)
 	case "==":
 		return nativeBoolToPyMonkeyBoolean(leftVal == rightVal)
 	case "!=":
@@ -518,16 +1108,43 @@ func evalIntegerInfixExpression(
 	}
 }
 
+func isNumeric(obj Object) bool {
+	switch obj.(type) {
+	case *Integer, *BigInt, *Float:
+		return true
+	default:
+		return false
+	}
+}
+
+func toFloat(obj Object) float64 {
+	switch obj := obj.(type) {
+	case *Integer:
+		return float64(obj.Value)
+	case *BigInt:
+		f, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return f
+	case *Float:
+		return obj.Value
+	default:
+		return 0
+	}
+}
+
 func evalStringInfixExpression(
 	operator string,
 	left, right Object,
+	env *Environment,
 ) Object {
 	leftVal := left.(*String).Value
 	rightVal := right.(*String).Value
 
 	switch operator {
 	case "+":
-		return &String{Value: leftVal + rightVal}
+		if err := env.runtime.checkStringLength(len(leftVal) + len(rightVal)); err != nil {
+			return err
+		}
+		return env.runtime.track(&String{Value: leftVal + rightVal}, left, right)
 	case "==":
 		return nativeBoolToPyMonkeyBoolean(leftVal == rightVal)
 	case "!=":
@@ -551,14 +1168,34 @@ func evalBangOperatorExpression(right Object) Object {
 }
 
 func evalMinusPrefixOperatorExpression(right Object) Object {
-	if right.Type() != INTEGER_OBJ {
+	switch right := right.(type) {
+	case *Integer:
+		if right.Value == math.MinInt64 {
+			return &BigInt{Value: new(big.Int).Neg(big.NewInt(right.Value))}
+		}
+		return &Integer{Value: -right.Value}
+	case *BigInt:
+		return normalizeBigInt(new(big.Int).Neg(right.Value))
+	case *Float:
+		return &Float{Value: -right.Value}
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
+}
 
-	value := right.(*Integer).Value
-	return &Integer{Value: -value}
+func evalTildePrefixOperatorExpression(right Object) Object {
+	switch right := right.(type) {
+	case *Integer:
+		return &Integer{Value: ^right.Value}
+	case *BigInt:
+		return normalizeBigInt(new(big.Int).Not(right.Value))
+	default:
+		return newError("unknown operator: ~%s", right.Type())
+	}
 }
 
+// evalIfExpression runs each branch in its own scope, like loop bodies, so a
+// let inside a branch neither leaks out nor clashes with an outer binding.
 func evalIfExpression(ie *ast.IfExpression, env *Environment) Object {
 	condition := Eval(ie.Condition, env)
 	if isError(condition) {
@@ -566,28 +1203,39 @@ func evalIfExpression(ie *ast.IfExpression, env *Environment) Object {
 	}
 
 	if isTruthy(condition) {
-		return Eval(ie.Consequence, env)
-	} else if ie.Alternative != nil {
-		return Eval(ie.Alternative, env)
-	} else {
-		return NULL
+		return Eval(ie.Consequence, NewEnclosedEnvironment(env))
+	}
+
+	for _, branch := range ie.ElseIfs {
+		condition := Eval(branch.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if isTruthy(condition) {
+			return Eval(branch.Consequence, NewEnclosedEnvironment(env))
+		}
 	}
+
+	if ie.Alternative != nil {
+		return Eval(ie.Alternative, NewEnclosedEnvironment(env))
+	}
+
+	return NULL
 }
 
 func evalIdentifier(
 	node *ast.Identifier,
 	env *Environment,
 ) Object {
-	if builtin, ok := builtins[node.Value]; ok {
-		return builtin
+	if val, ok := env.Get(node.Value); ok {
+		return val
 	}
 
-	val, ok := env.Get(node.Value)
-	if !ok {
-		return newError("identifier not found: " + node.Value)
+	if builtin, ok := env.runtime.builtins.Lookup(node.Value); ok {
+		return builtin
 	}
 
-	return val
+	return newError("identifier not found: " + node.Value)
 }
 
 func evalExpressions(
@@ -607,11 +1255,11 @@ func evalExpressions(
 	return result
 }
 
-func applyFunction(fn Object, args []Object) Object {
-	return applyFunctionWithThis(fn, args, nil)
+func applyFunction(fn Object, args []Object, site types.Position, env *Environment) Object {
+	return applyFunctionWithThis(fn, args, nil, site, env)
 }
 
-func applyFunctionWithThis(fn Object, args []Object, thisObj Object) Object {
+func applyFunctionWithThis(fn Object, args []Object, thisObj Object, site types.Position, env *Environment) Object {
 	switch fn := fn.(type) {
 
 	case *Function:
@@ -619,17 +1267,24 @@ func applyFunctionWithThis(fn Object, args []Object, thisObj Object) Object {
 		if thisObj != nil {
 			extendedEnv.Set("this", thisObj)
 		}
-		evaluated := Eval(fn.Body, extendedEnv)
+		evaluated := env.runtime.call(fn.Name, site, func() Object {
+			return Eval(fn.Body, extendedEnv)
+		})
 		return unwrapReturnValue(evaluated)
 
 	case *BoundMethod:
 		extendedEnv := extendFunctionEnv(fn.Method, args)
 		extendedEnv.Set("this", fn.Instance)
-		evaluated := Eval(fn.Method.Body, extendedEnv)
+		evaluated := env.runtime.call(fn.Method.Name, site, func() Object {
+			return Eval(fn.Method.Body, extendedEnv)
+		})
 		return unwrapReturnValue(evaluated)
 
 	case *Builtin:
-		return fn.Fn(args...)
+		if err := fn.checkArgs(args); err != nil {
+			return err
+		}
+		return env.runtime.track(fn.Fn(args...), args...)
 
 	default:
 		return newError("not a function: %T", fn)
@@ -644,7 +1299,9 @@ func extendFunctionEnv(
 
 	for paramIdx, param := range fn.Parameters {
 		if paramIdx < len(args) {
-			env.Set(param.Value, args[paramIdx])
+			env.define(param.Value, args[paramIdx])
+		} else {
+			env.define(param.Value, NULL)
 		}
 	}
 
@@ -672,7 +1329,13 @@ func isTruthy(obj Object) bool {
 }
 
 func newError(format string, a ...interface{}) *Error {
-	return &Error{Message: fmt.Sprintf(format, a...)}
+	return &Error{Kind: RUNTIME_ERR, Message: fmt.Sprintf(format, a...)}
+}
+
+func newErrorAt(node ast.Node, format string, a ...interface{}) *Error {
+	err := newError(format, a...)
+	err.Pos, err.End = nodeSpan(node)
+	return err
 }
 
 func isError(obj Object) bool {
@@ -0,0 +1,53 @@
+package run
+
+import (
+	"context"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/ast"
+	"strings"
+	"testing"
+)
+
+func TestEvalReportsFaultsAsErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+	}{
+		{"1 / 0", "division by zero"},
+		{"1 % 0", "division by zero"},
+		{"let x = 1; x()", "not a function"},
+	}
+
+	for _, tt := range tests {
+		expectError(t, testEval(t, context.Background(), NewRuntime(), tt.input), RUNTIME_ERR, tt.message)
+	}
+}
+
+func TestEvalMissingNode(t *testing.T) {
+	var stmt *ast.ExpressionStatement
+	result := Eval(stmt, NewEnvironment())
+	expectError(t, result, RUNTIME_ERR, "cannot evaluate missing node")
+
+	program := &ast.Program{Statements: []ast.Statement{&ast.ExpressionStatement{}}}
+	result = Eval(program, NewEnvironment())
+	expectError(t, result, RUNTIME_ERR, "cannot evaluate missing node")
+}
+
+func TestInspectDeeplyNested(t *testing.T) {
+	input := "let a = []; let i = 0; while (i < 100000) { a = [a]; i++ }; str(a)"
+	result := testEval(t, context.Background(), NewRuntime(), input)
+
+	s, ok := result.(*String)
+	if !ok {
+		t.Fatalf("expected *String, got %s", result.Inspect())
+	}
+	if !strings.Contains(s.Value, "[...]") {
+		t.Errorf("nesting was not cut off")
+	}
+}
+
+func TestInspectCycle(t *testing.T) {
+	result := testEval(t, context.Background(), NewRuntime(), "let a = [0]; a[0] = a; str(a)")
+	if s, ok := result.(*String); !ok || s.Value != "[[...]]" {
+		t.Fatalf("wrong result. got=%s", result.Inspect())
+	}
+}
@@ -0,0 +1,588 @@
+package run
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var (
+	objectType = reflect.TypeOf((*Object)(nil)).Elem()
+	errorType  = reflect.TypeOf((*error)(nil)).Elem()
+	bigIntType = reflect.TypeOf((*big.Int)(nil))
+)
+
+func ToObject(value interface{}) (Object, error) {
+	if value == nil {
+		return NULL, nil
+	}
+	return toObject(reflect.ValueOf(value), nil)
+}
+
+func toObject(v reflect.Value, visiting map[visit]bool) (Object, error) {
+	if !v.IsValid() {
+		return NULL, nil
+	}
+
+	if v.Type().Implements(objectType) {
+		if isNilValue(v) {
+			return NULL, nil
+		}
+		return v.Interface().(Object), nil
+	}
+
+	if v.Type() == bigIntType {
+		if v.IsNil() {
+			return NULL, nil
+		}
+		return normalizeBigInt(new(big.Int).Set(v.Interface().(*big.Int))), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return nativeBoolToPyMonkeyBoolean(v.Bool()), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Integer{Value: v.Int()}, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return normalizeBigInt(new(big.Int).SetUint64(v.Uint())), nil
+
+	case reflect.Float32, reflect.Float64:
+		return &Float{Value: v.Float()}, nil
+
+	case reflect.String:
+		return &String{Value: v.String()}, nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return NULL, nil
+		}
+		if v.Kind() == reflect.Slice {
+			var leave func()
+			var err error
+			if visiting, leave, err = enterValue(v, visiting); err != nil {
+				return nil, err
+			}
+			defer leave()
+		}
+		elements := make([]Object, v.Len())
+		for i := range elements {
+			element, err := toObject(v.Index(i), visiting)
+			if err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+			elements[i] = element
+		}
+		return &Array{Elements: elements}, nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return NULL, nil
+		}
+		visiting, leave, err := enterValue(v, visiting)
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+		pairs := make(map[string]HashPair, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := toObject(iter.Key(), visiting)
+			if err != nil {
+				return nil, fmt.Errorf("map key: %w", err)
+			}
+			value, err := toObject(iter.Value(), visiting)
+			if err != nil {
+				return nil, fmt.Errorf("map value %s: %w", key.Inspect(), err)
+			}
+			pairs[key.Inspect()] = HashPair{Key: key, Value: value}
+		}
+		return &Hash{Pairs: pairs}, nil
+
+	case reflect.Struct:
+		pairs := make(map[string]HashPair)
+		for _, field := range structFields(v.Type()) {
+			value, err := toObject(v.FieldByIndex(field.index), visiting)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", field.name, err)
+			}
+			pairs[field.name] = HashPair{Key: &String{Value: field.name}, Value: value}
+		}
+		return &Hash{Pairs: pairs}, nil
+
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return NULL, nil
+		}
+		if v.Kind() == reflect.Ptr {
+			var leave func()
+			var err error
+			if visiting, leave, err = enterValue(v, visiting); err != nil {
+				return nil, err
+			}
+			defer leave()
+		}
+		return toObject(v.Elem(), visiting)
+
+	case reflect.Func:
+		if v.IsNil() {
+			return NULL, nil
+		}
+		return WrapFunc(v.Interface())
+	}
+
+	return nil, fmt.Errorf("cannot convert %s to an object", v.Type())
+}
+
+func FromObject(obj Object, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("target must be a non-nil pointer, got %T", target)
+	}
+	return fromObject(obj, v.Elem(), nil)
+}
+
+func fromObject(obj Object, dst reflect.Value, visiting map[Object]bool) error {
+	if obj == nil {
+		obj = NULL
+	}
+
+	isAny := dst.Kind() == reflect.Interface && dst.NumMethod() == 0
+	if !isAny && reflect.TypeOf(obj).AssignableTo(dst.Type()) {
+		dst.Set(reflect.ValueOf(obj))
+		return nil
+	}
+
+	if dst.Type() == bigIntType && isIntegral(obj) {
+		dst.Set(reflect.ValueOf(new(big.Int).Set(toBigInt(obj))))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		var leave func()
+		var err error
+		if visiting, leave, err = enterContainer(obj, visiting); err != nil {
+			return err
+		}
+		defer leave()
+	}
+
+	switch dst.Kind() {
+	case reflect.Interface:
+		if !isAny {
+			break
+		}
+		value, err := nativeValue(obj, visiting)
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+		} else {
+			dst.Set(reflect.ValueOf(value))
+		}
+		return nil
+
+	case reflect.Ptr:
+		if obj == NULL {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		elem := reflect.New(dst.Type().Elem())
+		if err := fromObject(obj, elem.Elem(), visiting); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+
+	case reflect.Bool:
+		if b, ok := obj.(*Boolean); ok {
+			dst.SetBool(b.Value)
+			return nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, ok := obj.(*Integer); ok {
+			if dst.OverflowInt(i.Value) {
+				return fmt.Errorf("value %d overflows %s", i.Value, dst.Type())
+			}
+			dst.SetInt(i.Value)
+			return nil
+		}
+		if b, ok := obj.(*BigInt); ok {
+			return fmt.Errorf("value %s overflows %s", b.Inspect(), dst.Type())
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if i, ok := obj.(*Integer); ok {
+			if i.Value < 0 || dst.OverflowUint(uint64(i.Value)) {
+				return fmt.Errorf("value %d overflows %s", i.Value, dst.Type())
+			}
+			dst.SetUint(uint64(i.Value))
+			return nil
+		}
+		if b, ok := obj.(*BigInt); ok {
+			if b.Value.Sign() < 0 || !b.Value.IsUint64() || dst.OverflowUint(b.Value.Uint64()) {
+				return fmt.Errorf("value %s overflows %s", b.Inspect(), dst.Type())
+			}
+			dst.SetUint(b.Value.Uint64())
+			return nil
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if isNumeric(obj) {
+			dst.SetFloat(toFloat(obj))
+			return nil
+		}
+
+	case reflect.String:
+		if s, ok := obj.(*String); ok {
+			dst.SetString(s.Value)
+			return nil
+		}
+
+	case reflect.Slice:
+		if obj == NULL {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		if arr, ok := obj.(*Array); ok {
+			slice := reflect.MakeSlice(dst.Type(), len(arr.Elements), len(arr.Elements))
+			for i, element := range arr.Elements {
+				if err := fromObject(element, slice.Index(i), visiting); err != nil {
+					return fmt.Errorf("index %d: %w", i, err)
+				}
+			}
+			dst.Set(slice)
+			return nil
+		}
+
+	case reflect.Array:
+		if arr, ok := obj.(*Array); ok {
+			if len(arr.Elements) != dst.Len() {
+				return fmt.Errorf("cannot convert ARRAY of length %d to %s", len(arr.Elements), dst.Type())
+			}
+			for i, element := range arr.Elements {
+				if err := fromObject(element, dst.Index(i), visiting); err != nil {
+					return fmt.Errorf("index %d: %w", i, err)
+				}
+			}
+			return nil
+		}
+
+	case reflect.Map:
+		if obj == NULL {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		if h, ok := obj.(*Hash); ok {
+			m := reflect.MakeMapWithSize(dst.Type(), len(h.Pairs))
+			for _, pair := range h.Pairs {
+				key := reflect.New(dst.Type().Key()).Elem()
+				if err := fromObject(pair.Key, key, visiting); err != nil {
+					return fmt.Errorf("map key: %w", err)
+				}
+				value := reflect.New(dst.Type().Elem()).Elem()
+				if err := fromObject(pair.Value, value, visiting); err != nil {
+					return fmt.Errorf("map value %s: %w", pair.Key.Inspect(), err)
+				}
+				m.SetMapIndex(key, value)
+			}
+			dst.Set(m)
+			return nil
+		}
+
+	case reflect.Struct:
+		var lookup func(name string) (Object, bool)
+		switch obj := obj.(type) {
+		case *Hash:
+			lookup = func(name string) (Object, bool) {
+				pair, ok := obj.Pairs[name]
+				return pair.Value, ok
+			}
+		case *Instance:
+			lookup = func(name string) (Object, bool) {
+				value, ok := obj.Properties[name]
+				return value, ok
+			}
+		}
+		if lookup == nil {
+			break
+		}
+		for _, field := range structFields(dst.Type()) {
+			value, ok := lookup(field.name)
+			if !ok {
+				continue
+			}
+			if err := fromObject(value, dst.FieldByIndex(field.index), visiting); err != nil {
+				return fmt.Errorf("field %s: %w", field.name, err)
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("cannot convert %s to %s", obj.Type(), dst.Type())
+}
+
+func nativeValue(obj Object, visiting map[Object]bool) (interface{}, error) {
+	visiting, leave, err := enterContainer(obj, visiting)
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	switch obj := obj.(type) {
+	case *Null:
+		return nil, nil
+	case *Boolean:
+		return obj.Value, nil
+	case *Integer:
+		return obj.Value, nil
+	case *BigInt:
+		return new(big.Int).Set(obj.Value), nil
+	case *Float:
+		return obj.Value, nil
+	case *String:
+		return obj.Value, nil
+	case *Array:
+		values := make([]interface{}, len(obj.Elements))
+		for i, element := range obj.Elements {
+			value, err := nativeValue(element, visiting)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	case *Hash:
+		values := make(map[string]interface{}, len(obj.Pairs))
+		for key, pair := range obj.Pairs {
+			value, err := nativeValue(pair.Value, visiting)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = value
+		}
+		return values, nil
+	case *Instance:
+		values := make(map[string]interface{}, len(obj.Properties))
+		for key, property := range obj.Properties {
+			if _, ok := property.(*Function); ok {
+				continue
+			}
+			value, err := nativeValue(property, visiting)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = value
+		}
+		return values, nil
+	default:
+		return obj, nil
+	}
+}
+
+// enterContainer marks an array, hash or instance as being converted, failing
+// when it is already being converted further up, i.e. when it contains itself,
+// or when it is nested deeper than maxNestingDepth.
+func enterContainer(obj Object, visiting map[Object]bool) (map[Object]bool, func(), error) {
+	switch obj.(type) {
+	case *Array, *Hash, *Instance:
+	default:
+		return visiting, func() {}, nil
+	}
+
+	if visiting[obj] {
+		return visiting, nil, fmt.Errorf("cannot convert %s that contains itself", obj.Type())
+	}
+	if len(visiting) >= maxNestingDepth {
+		return visiting, nil, fmt.Errorf("cannot convert %s nested more than %d levels deep", obj.Type(), maxNestingDepth)
+	}
+	if visiting == nil {
+		visiting = map[Object]bool{}
+	}
+	visiting[obj] = true
+	return visiting, func() { delete(visiting, obj) }, nil
+}
+
+// visit identifies a pointer, map or slice that toObject is converting. The
+// type is part of the key because a struct and its first field, or a slice
+// and a shorter slice of it, share an address.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// enterValue is the Go side counterpart of enterContainer: it fails when v is
+// already being converted further up, or when the pointers, maps and slices
+// being followed are nested deeper than maxNestingDepth.
+func enterValue(v reflect.Value, visiting map[visit]bool) (map[visit]bool, func(), error) {
+	key := visit{ptr: v.Pointer(), typ: v.Type()}
+	if visiting[key] {
+		return visiting, nil, fmt.Errorf("cannot convert %s that contains itself", v.Type())
+	}
+	if len(visiting) >= maxNestingDepth {
+		return visiting, nil, fmt.Errorf("cannot convert %s nested more than %d levels deep", v.Type(), maxNestingDepth)
+	}
+	if visiting == nil {
+		visiting = map[visit]bool{}
+	}
+	visiting[key] = true
+	return visiting, func() { delete(visiting, key) }, nil
+}
+
+type structField struct {
+	name  string
+	index []int
+}
+
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("sho"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		fields = append(fields, structField{name: name, index: f.Index})
+	}
+
+	return fields
+}
+
+func WrapFunc(fn interface{}) (*Builtin, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("expected a function, got %T", fn)
+	}
+
+	if native, ok := fn.(func(args ...Object) Object); ok {
+		return &Builtin{Params: []Param{{Name: "args", Variadic: true}}, Fn: native}, nil
+	}
+
+	t := v.Type()
+	if t.NumOut() > 2 || (t.NumOut() == 2 && t.Out(1) != errorType) {
+		return nil, fmt.Errorf("function %s must return at most a value and an error", t)
+	}
+
+	params := make([]Param, t.NumIn())
+	for i := range params {
+		paramType := t.In(i)
+		variadic := t.IsVariadic() && i == len(params)-1
+		if variadic {
+			paramType = paramType.Elem()
+		}
+		params[i] = Param{
+			Name:     fmt.Sprintf("arg%d", i+1),
+			Types:    paramTypes(paramType),
+			Variadic: variadic,
+		}
+	}
+
+	return &Builtin{Params: params, Fn: func(args ...Object) Object {
+		return callGoFunc(v, args)
+	}}, nil
+}
+
+// paramTypes lists the object types fromObject can convert to t, or nil when
+// it cannot tell from the kind alone and leaves the check to fromObject.
+func paramTypes(t reflect.Type) []ObjectType {
+	if t.Implements(objectType) {
+		return nil
+	}
+	if t == bigIntType {
+		return []ObjectType{INTEGER_OBJ, BIGINT_OBJ, NULL_OBJ}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return []ObjectType{BOOLEAN_OBJ}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return []ObjectType{INTEGER_OBJ, BIGINT_OBJ}
+	case reflect.Float32, reflect.Float64:
+		return []ObjectType{INTEGER_OBJ, BIGINT_OBJ, FLOAT_OBJ}
+	case reflect.String:
+		return []ObjectType{STRING_OBJ}
+	case reflect.Slice:
+		return []ObjectType{ARRAY_OBJ, NULL_OBJ}
+	case reflect.Array:
+		return []ObjectType{ARRAY_OBJ}
+	case reflect.Map:
+		return []ObjectType{HASH_OBJ, NULL_OBJ}
+	case reflect.Struct:
+		return []ObjectType{HASH_OBJ, INSTANCE_OBJ}
+	case reflect.Ptr:
+		types := paramTypes(t.Elem())
+		if types == nil || slices.Contains(types, NULL_OBJ) {
+			return types
+		}
+		return append(types, NULL_OBJ)
+	default:
+		return nil
+	}
+}
+
+func callGoFunc(fn reflect.Value, args []Object) Object {
+	t := fn.Type()
+	params := t.NumIn()
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		var paramType reflect.Type
+		if t.IsVariadic() && i >= params-1 {
+			paramType = t.In(params - 1).Elem()
+		} else {
+			paramType = t.In(i)
+		}
+
+		value := reflect.New(paramType).Elem()
+		if err := fromObject(arg, value, nil); err != nil {
+			return newError("argument %d: %s", i+1, err)
+		}
+		in[i] = value
+	}
+
+	out := fn.Call(in)
+
+	if len(out) > 0 && t.Out(len(out)-1) == errorType {
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			return newError("%s", err)
+		}
+		out = out[:len(out)-1]
+	}
+
+	if len(out) == 0 {
+		return NULL
+	}
+
+	result, err := toObject(out[0], nil)
+	if err != nil {
+		return newError("result: %s", err)
+	}
+	return result
+}
+
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
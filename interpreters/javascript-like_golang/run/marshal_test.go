@@ -0,0 +1,31 @@
+package run
+
+import (
+	"strings"
+	"testing"
+)
+
+type node struct {
+	Next *node
+}
+
+func TestToObjectCycle(t *testing.T) {
+	n := &node{}
+	n.Next = n
+
+	_, err := ToObject(n)
+	if err == nil || !strings.Contains(err.Error(), "contains itself") {
+		t.Fatalf("expected a cycle error, got %v", err)
+	}
+}
+
+func TestFromObjectCycle(t *testing.T) {
+	arr := &Array{Elements: []Object{NULL}}
+	arr.Elements[0] = arr
+
+	var target interface{}
+	err := FromObject(arr, &target)
+	if err == nil || !strings.Contains(err.Error(), "contains itself") {
+		t.Fatalf("expected a cycle error, got %v", err)
+	}
+}
@@ -3,8 +3,12 @@ package run
 import (
 	"bytes"
 	"fmt"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/ast"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/types"
 	"hash/fnv"
-	"main/ast"
+	"math"
+	"math/big"
+	"strconv"
 	"strings"
 )
 
@@ -12,9 +16,13 @@ type ObjectType string
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	BIGINT_OBJ       = "BIGINT"
+	FLOAT_OBJ        = "FLOAT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
 	ERROR_OBJ        = "ERROR"
 	FUNCTION_OBJ     = "FUNCTION"
 	STRING_OBJ       = "STRING"
@@ -38,6 +46,26 @@ type Integer struct {
 func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 
+type BigInt struct {
+	Value *big.Int
+}
+
+func (b *BigInt) Inspect() string  { return b.Value.String() }
+func (b *BigInt) Type() ObjectType { return BIGINT_OBJ }
+
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
 type Boolean struct {
 	Value bool
 }
@@ -57,14 +85,45 @@ type ReturnValue struct {
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 
+type Break struct{}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "break" }
+
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "continue" }
+
+type ErrorKind string
+
+const (
+	RUNTIME_ERR    ErrorKind = "RuntimeError"
+	CANCELLED_ERR  ErrorKind = "CancelledError"
+	TIMEOUT_ERR    ErrorKind = "TimeoutError"
+	STEP_LIMIT_ERR ErrorKind = "StepLimitError"
+	RESOURCE_ERR   ErrorKind = "ResourceLimitError"
+)
+
 type Error struct {
+	Kind    ErrorKind
 	Message string
+	Pos     types.Position
+	End     types.Position
+	Stack   []Frame
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
+func (e *Error) Error() string {
+	if e.Pos.IsValid() {
+		return e.Pos.String() + ": " + e.Message
+	}
+	return e.Message
+}
 
 type Function struct {
+	Name       string
 	Parameters []*ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
@@ -94,28 +153,21 @@ func (s *String) Type() ObjectType { return STRING_OBJ }
 func (s *String) Inspect() string  { return s.Value }
 
 type Builtin struct {
-	Fn func(args ...Object) Object
+	Name        string
+	Description string
+	Params      []Param
+	Fn          func(args ...Object) Object
 }
 
 func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
-func (b *Builtin) Inspect() string  { return "builtin function" }
+func (b *Builtin) Inspect() string  { return "builtin function " + b.displayName() }
 
 type Array struct {
 	Elements []Object
 }
 
 func (ao *Array) Type() ObjectType { return ARRAY_OBJ }
-func (ao *Array) Inspect() string {
-	var out bytes.Buffer
-	elements := []string{}
-	for _, e := range ao.Elements {
-		elements = append(elements, e.Inspect())
-	}
-	out.WriteString("[")
-	out.WriteString(strings.Join(elements, ", "))
-	out.WriteString("]")
-	return out.String()
-}
+func (ao *Array) Inspect() string  { return inspect(ao, map[Object]bool{}) }
 
 type HashPair struct {
 	Key   Object
@@ -127,16 +179,52 @@ type Hash struct {
 }
 
 func (h *Hash) Type() ObjectType { return HASH_OBJ }
-func (h *Hash) Inspect() string {
+func (h *Hash) Inspect() string  { return inspect(h, map[Object]bool{}) }
+
+// maxNestingDepth bounds how far inspect and the marshalling functions descend
+// into nested arrays and hashes. Deeper recursion could overflow the Go stack,
+// which is fatal rather than a panic that Eval can recover.
+const maxNestingDepth = 10000
+
+// inspect prints arrays and hashes that contain themselves, or that are nested
+// deeper than maxNestingDepth, as [...] and {...}.
+func inspect(obj Object, visiting map[Object]bool) string {
 	var out bytes.Buffer
-	pairs := []string{}
-	for _, pair := range h.Pairs {
-		pairs = append(pairs, fmt.Sprintf("%s: %s",
-			pair.Key.Inspect(), pair.Value.Inspect()))
+
+	switch obj := obj.(type) {
+	case *Array:
+		if visiting[obj] || len(visiting) >= maxNestingDepth {
+			return "[...]"
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		elements := []string{}
+		for _, e := range obj.Elements {
+			elements = append(elements, inspect(e, visiting))
+		}
+		out.WriteString("[")
+		out.WriteString(strings.Join(elements, ", "))
+		out.WriteString("]")
+	case *Hash:
+		if visiting[obj] || len(visiting) >= maxNestingDepth {
+			return "{...}"
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		pairs := []string{}
+		for _, pair := range obj.Pairs {
+			pairs = append(pairs, fmt.Sprintf("%s: %s",
+				inspect(pair.Key, visiting), inspect(pair.Value, visiting)))
+		}
+		out.WriteString("{")
+		out.WriteString(strings.Join(pairs, ", "))
+		out.WriteString("}")
+	default:
+		return obj.Inspect()
 	}
-	out.WriteString("{")
-	out.WriteString(strings.Join(pairs, ", "))
-	out.WriteString("}")
+
 	return out.String()
 }
 
@@ -189,6 +277,16 @@ func (i *Integer) HashKey() HashKey {
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
 
+func (b *BigInt) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write(b.Value.Bytes())
+	return HashKey{Type: b.Type(), Value: h.Sum64() ^ uint64(b.Value.Sign()+1)}
+}
+
+func (f *Float) HashKey() HashKey {
+	return HashKey{Type: f.Type(), Value: math.Float64bits(f.Value)}
+}
+
 func (s *String) HashKey() HashKey {
 	h := fnv.New64a()
 	h.Write([]byte(s.Value))
@@ -199,4 +297,7 @@ var (
 	NULL  = &Null{}
 	TRUE  = &Boolean{Value: true}
 	FALSE = &Boolean{Value: false}
+
+	BREAK    = &Break{}
+	CONTINUE = &Continue{}
 )
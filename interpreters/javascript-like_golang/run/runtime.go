@@ -0,0 +1,265 @@
+package run
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/types"
+	"io"
+	"os"
+	"strings"
+)
+
+type Frame struct {
+	Function string
+	Pos      types.Position
+}
+
+const (
+	DefaultMaxCallDepth = 10000
+
+	maxEvalDepth = 100000
+)
+
+type Runtime struct {
+	MaxCallDepth int
+	MaxSteps     int64
+	// MaxMemory bounds how much a script grows its data rather than how much
+	// it holds at once: each operation is charged for the approximate bytes
+	// its result adds beyond the largest value it was built from, so pushing
+	// onto an array or appending to a string costs only what was added.
+	// Literals are part of the program and are never charged, and nothing is
+	// credited back when a value becomes garbage.
+	MaxMemory         int64
+	MaxStringLength   int
+	MaxCollectionSize int
+
+	Stdout io.Writer
+	Stderr io.Writer
+	Stdin  io.Reader
+
+	stdin     *bufio.Reader
+	stdinFrom io.Reader
+	pending   chan lineResult
+	builtins  *Builtins
+	frames    []Frame
+	depth     int
+	steps     int64
+	allocated int64
+	ctx       context.Context
+}
+
+func NewRuntime() *Runtime {
+	rt := &Runtime{
+		MaxCallDepth: DefaultMaxCallDepth,
+		Stdout:       os.Stdout,
+		Stderr:       os.Stderr,
+		Stdin:        os.Stdin,
+	}
+	rt.builtins = DefaultBuiltins(rt)
+	return rt
+}
+
+func (rt *Runtime) Builtins() *Builtins {
+	return rt.builtins
+}
+
+func (rt *Runtime) Steps() int64 {
+	return rt.steps
+}
+
+func (rt *Runtime) Allocated() int64 {
+	return rt.allocated
+}
+
+func (rt *Runtime) StackTrace() []Frame {
+	stack := make([]Frame, len(rt.frames))
+	copy(stack, rt.frames)
+	return stack
+}
+
+func (rt *Runtime) call(name string, site types.Position, body func() Object) Object {
+	if name == "" {
+		name = "<anonymous>"
+	}
+
+	if err := rt.step(); err != nil {
+		return err
+	}
+	if rt.MaxCallDepth > 0 && len(rt.frames) >= rt.MaxCallDepth {
+		return newError("maximum call stack exceeded")
+	}
+
+	rt.frames = append(rt.frames, Frame{Function: name, Pos: site})
+	result := body()
+	rt.frames = rt.frames[:len(rt.frames)-1]
+
+	return result
+}
+
+func (rt *Runtime) step() *Error {
+	rt.steps++
+	if rt.MaxSteps > 0 && rt.steps > rt.MaxSteps {
+		return &Error{Kind: STEP_LIMIT_ERR, Message: "step budget exhausted"}
+	}
+
+	if rt.ctx != nil {
+		select {
+		case <-rt.ctx.Done():
+			return contextError(rt.ctx.Err())
+		default:
+		}
+	}
+
+	return nil
+}
+
+func contextError(err error) *Error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Kind: TIMEOUT_ERR, Message: "execution timed out"}
+	}
+	return &Error{Kind: CANCELLED_ERR, Message: "execution cancelled"}
+}
+
+const (
+	objectSize   = 16
+	elementSize  = 16
+	hashPairSize = 64
+)
+
+// track checks obj against the size limits and charges the memory budget for
+// its growth over from.
+func (rt *Runtime) track(obj Object, from ...Object) Object {
+	if err := rt.checkLimits(obj); err != nil {
+		return err
+	}
+
+	size := sizeOf(obj)
+	for _, src := range from {
+		size = min(size, sizeOf(obj)-sizeOf(src))
+	}
+	if size <= 0 {
+		return obj
+	}
+
+	if err := rt.allocate(size); err != nil {
+		return err
+	}
+
+	return obj
+}
+
+func (rt *Runtime) checkLimits(obj Object) *Error {
+	switch obj := obj.(type) {
+	case *String:
+		return rt.checkStringLength(len(obj.Value))
+	case *Array:
+		return rt.checkCollectionSize(len(obj.Elements))
+	case *Hash:
+		return rt.checkCollectionSize(len(obj.Pairs))
+	default:
+		return nil
+	}
+}
+
+func sizeOf(obj Object) int64 {
+	switch obj := obj.(type) {
+	case *String:
+		return objectSize + int64(len(obj.Value))
+	case *Array:
+		return objectSize + int64(len(obj.Elements))*elementSize
+	case *Hash:
+		return objectSize + int64(len(obj.Pairs))*hashPairSize
+	case *BigInt:
+		return objectSize + int64(obj.Value.BitLen()/8)
+	default:
+		return 0
+	}
+}
+
+func (rt *Runtime) allocate(size int64) *Error {
+	rt.allocated += size
+	if rt.MaxMemory > 0 && rt.allocated > rt.MaxMemory {
+		return resourceError("memory limit of %d bytes exceeded", rt.MaxMemory)
+	}
+	return nil
+}
+
+func (rt *Runtime) checkStringLength(length int) *Error {
+	if rt.MaxStringLength > 0 && length > rt.MaxStringLength {
+		return resourceError("string length %d exceeds limit of %d", length, rt.MaxStringLength)
+	}
+	return nil
+}
+
+func (rt *Runtime) checkCollectionSize(size int) *Error {
+	if rt.MaxCollectionSize > 0 && size > rt.MaxCollectionSize {
+		return resourceError("collection size %d exceeds limit of %d", size, rt.MaxCollectionSize)
+	}
+	return nil
+}
+
+func resourceError(format string, a ...interface{}) *Error {
+	return &Error{Kind: RESOURCE_ERR, Message: fmt.Sprintf(format, a...)}
+}
+
+type lineResult struct {
+	line string
+	err  error
+}
+
+// ReadLine reads a line from Stdin without its line ending, returning io.EOF
+// once the input is exhausted. When ctx is done first it returns ctx.Err() and
+// leaves the read pending, so the next call returns that line instead of
+// losing it.
+func (rt *Runtime) ReadLine(ctx context.Context) (string, error) {
+	if rt.pending == nil {
+		if rt.stdin == nil || rt.stdinFrom != rt.Stdin {
+			rt.stdin = bufio.NewReader(rt.Stdin)
+			rt.stdinFrom = rt.Stdin
+		}
+
+		pending := make(chan lineResult, 1)
+		stdin := rt.stdin
+		go func() {
+			line, err := stdin.ReadString('\n')
+			pending <- lineResult{line: line, err: err}
+		}()
+		rt.pending = pending
+	}
+
+	var result lineResult
+	select {
+	case result = <-rt.pending:
+		rt.pending = nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	if result.err != nil && (result.err != io.EOF || result.line == "") {
+		return "", result.err
+	}
+
+	line := strings.TrimSuffix(result.line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+func (rt *Runtime) readLine() Object {
+	ctx := rt.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	line, err := rt.ReadLine(ctx)
+	switch {
+	case err == io.EOF:
+		return NULL
+	case err != nil && ctx.Err() != nil:
+		return contextError(ctx.Err())
+	case err != nil:
+		return newError("cannot read input: %s", err)
+	}
+
+	return rt.track(&String{Value: line})
+}
@@ -0,0 +1,143 @@
+package run
+
+import (
+	"context"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/lexer"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/parser"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEval(t *testing.T, ctx context.Context, rt *Runtime, input string) Object {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) != 0 {
+		t.Fatalf("parse %q: %v", input, errs)
+	}
+
+	return EvalContext(ctx, program, NewEnvironmentWithRuntime(rt))
+}
+
+func expectError(t *testing.T, obj Object, kind ErrorKind, message string) {
+	t.Helper()
+
+	err, ok := obj.(*Error)
+	if !ok {
+		t.Fatalf("expected *Error, got %T (%s)", obj, obj.Inspect())
+	}
+	if err.Kind != kind {
+		t.Errorf("wrong error kind. got=%s, want=%s", err.Kind, kind)
+	}
+	if !strings.Contains(err.Message, message) {
+		t.Errorf("wrong error message. got=%q, want it to contain %q", err.Message, message)
+	}
+}
+
+func TestStepBudget(t *testing.T) {
+	rt := NewRuntime()
+	rt.MaxSteps = 1000
+
+	result := testEval(t, context.Background(), rt, "while (true) {}")
+	expectError(t, result, STEP_LIMIT_ERR, "step budget exhausted")
+
+	// the budget is per evaluation, not per runtime
+	result = testEval(t, context.Background(), rt, "let i = 0; while (i < 10) { i++ }; i")
+	if isError(result) {
+		t.Fatalf("budget was not reset: %s", result.Inspect())
+	}
+}
+
+func TestCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := testEval(t, ctx, NewRuntime(), "1")
+	expectError(t, result, CANCELLED_ERR, "execution cancelled")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	result = testEval(t, ctx, NewRuntime(), "while (true) {}")
+	expectError(t, result, TIMEOUT_ERR, "execution timed out")
+}
+
+func TestReadLineCancellation(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+
+	rt := NewRuntime()
+	rt.Stdin = r
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	result := testEval(t, ctx, rt, "readLine()")
+	expectError(t, result, TIMEOUT_ERR, "execution timed out")
+
+	// the abandoned read hands its line to the next call
+	go w.Write([]byte("alice\n"))
+	result = testEval(t, context.Background(), rt, "readLine()")
+	if s, ok := result.(*String); !ok || s.Value != "alice" {
+		t.Fatalf("wrong line. got=%s", result.Inspect())
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+	}{
+		{`let s = "x"; while (true) { s = s + s }`, "memory limit of 100000 bytes exceeded"},
+		{`let a = []; while (true) { a = push(a, 1) }`, "memory limit of 100000 bytes exceeded"},
+		{`let h = {}; let i = 0; while (true) { h[i] = i; i++ }`, "memory limit of 100000 bytes exceeded"},
+		{`let n = 2; while (true) { n = n * n }`, "memory limit of 100000 bytes exceeded"},
+	}
+
+	for _, tt := range tests {
+		rt := NewRuntime()
+		rt.MaxMemory = 100000
+		expectError(t, testEval(t, context.Background(), rt, tt.input), RESOURCE_ERR, tt.message)
+	}
+}
+
+func TestMemoryLimitChargesGrowth(t *testing.T) {
+	tests := []string{
+		`let a = []; let i = 0; while (i < 1000) { a = push(a, i); i++ }; len(a)`,
+		`let i = 0; while (i < 100000) { "abcdefghij"; i++ }; i`,
+		`let s = ""; let i = 0; while (i < 1000) { s = s + "x"; i++ }; len(s)`,
+	}
+
+	for _, input := range tests {
+		rt := NewRuntime()
+		rt.MaxMemory = 1000000
+		if result := testEval(t, context.Background(), rt, input); isError(result) {
+			t.Errorf("%s: %s", input, result.Inspect())
+		}
+	}
+}
+
+func TestSizeLimits(t *testing.T) {
+	rt := NewRuntime()
+	rt.MaxStringLength = 10
+	result := testEval(t, context.Background(), rt, `"abcdef" + "ghijkl"`)
+	expectError(t, result, RESOURCE_ERR, "string length 12 exceeds limit of 10")
+
+	rt = NewRuntime()
+	rt.MaxCollectionSize = 3
+	result = testEval(t, context.Background(), rt, `push([1, 2, 3], 4)`)
+	expectError(t, result, RESOURCE_ERR, "collection size 4 exceeds limit of 3")
+}
+
+func TestCallDepthLimit(t *testing.T) {
+	rt := NewRuntime()
+	rt.MaxCallDepth = 50
+
+	result := testEval(t, context.Background(), rt, "let f = func(n) { f(n) }; f(1)")
+	expectError(t, result, RUNTIME_ERR, "maximum call stack exceeded")
+
+	result = testEval(t, context.Background(), rt, "let g = func(n) { if (n == 0) { return 0 }; return g(n - 1) }; g(40)")
+	if isError(result) {
+		t.Fatalf("recursion within the limit failed: %s", result.Inspect())
+	}
+}
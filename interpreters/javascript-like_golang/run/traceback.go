@@ -0,0 +1,62 @@
+package run
+
+import (
+	"fmt"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/diag"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/types"
+	"strings"
+)
+
+const maxTraceEntries = 10
+
+func (e *Error) Traceback(filename, source string) string {
+	var out strings.Builder
+
+	if len(e.Stack) > 0 {
+		out.WriteString("Traceback (most recent call last):\n")
+		function := "<main>"
+		for i, frame := range e.Stack {
+			if skipped := len(e.Stack) - 2*maxTraceEntries; skipped > 0 && i >= maxTraceEntries && i < len(e.Stack)-maxTraceEntries {
+				if i == maxTraceEntries {
+					fmt.Fprintf(&out, "  ... %d more calls ...\n", skipped)
+				}
+				function = frame.Function
+				continue
+			}
+			writeTraceEntry(&out, filename, source, frame.Pos, function)
+			function = frame.Function
+		}
+		writeTraceEntry(&out, filename, source, e.Pos, function)
+		out.WriteString("\n")
+	}
+
+	out.WriteString(location(filename, e.Pos))
+	out.WriteString(e.Message)
+
+	if frame := diag.CodeFrame(source, e.Pos, e.End); frame != "" {
+		out.WriteString("\n\n")
+		out.WriteString(strings.TrimSuffix(frame, "\n"))
+	}
+
+	return out.String()
+}
+
+func writeTraceEntry(out *strings.Builder, filename, source string, pos types.Position, function string) {
+	fmt.Fprintf(out, "  at %s (%s)\n", function, strings.TrimSuffix(location(filename, pos), ": "))
+	if line := strings.TrimSpace(diag.SourceLine(source, pos.Line)); line != "" {
+		fmt.Fprintf(out, "    %s\n", line)
+	}
+}
+
+func location(filename string, pos types.Position) string {
+	switch {
+	case filename != "" && pos.IsValid():
+		return fmt.Sprintf("%s:%s: ", filename, pos)
+	case filename != "":
+		return filename + ": "
+	case pos.IsValid():
+		return pos.String() + ": "
+	default:
+		return ""
+	}
+}
@@ -1,51 +1,153 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"main/lexer"
-	"main/parser"
-	"main/run"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/repl"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/run"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/sho"
+	"io"
+	"os"
 )
 
+const (
+	exitOK      = 0
+	exitUsage   = 64
+	exitSyntax  = 65
+	exitNoInput = 66
+	exitRuntime = 70
+)
+
+const usage = `usage: sho [-e expr] [file | -] [args...]
+       sho run <file | -> [args...]
+       sho repl
+
+Without a file or -e, the program is read from stdin.
+Script arguments are available to the program through the "args" global.
+
+flags:
+`
+
 func main() {
-	input := `
-		let add = func (x, y) {
-		  return x + y
+	os.Exit(runMain(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func runMain(argv []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("sho", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	expr := flags.String("e", "", "evaluate `expr` and print its result")
+	maxDepth := flags.Int("max-depth", run.DefaultMaxCallDepth, "maximum call stack `depth`, 0 for no limit")
+	maxSteps := flags.Int64("max-steps", 0, "abort after `n` evaluation steps, 0 for no limit")
+	maxMemory := flags.Int64("max-memory", 0, "abort after script data grows by about `bytes`, 0 for no limit")
+	timeout := flags.Duration("timeout", 0, "abort after `duration`, 0 for no limit")
+
+	if err := flags.Parse(argv); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
 		}
+		return exitUsage
+	}
 
-		let result = add(3, 4)
-		print(result);
+	opts := []sho.Option{
+		sho.WithStdout(stdout),
+		sho.WithStderr(stderr),
+		sho.WithStdin(stdin),
+		sho.WithMaxCallDepth(*maxDepth),
+		sho.WithMaxSteps(*maxSteps),
+		sho.WithMaxMemory(*maxMemory),
+		sho.WithTimeout(*timeout),
+	}
 
-		let arr = [1, 2, 3]
-		print(arr[1])
+	rest := flags.Args()
+	isExpr := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "e" {
+			isExpr = true
+		}
+	})
 
-		let obj = { "name": "Buddy", "age": 5 }
-		print(obj["name"])
+	if isExpr {
+		return execute(opts, "-e", *expr, rest, stdout, stderr, true)
+	}
+
+	if len(rest) > 0 && rest[0] == "repl" {
+		repl.Start(stdin, stdout, opts...)
+		return exitOK
+	}
 
-		if (result > 5) {
-		  print("big result")
-		} else {
-		  print("small result")
+	if len(rest) > 0 && rest[0] == "run" {
+		rest = rest[1:]
+		if len(rest) == 0 {
+			fmt.Fprintln(stderr, "sho: run: missing file operand")
+			flags.Usage()
+			return exitUsage
 		}
+	}
+
+	name := "-"
+	if len(rest) > 0 {
+		name, rest = rest[0], rest[1:]
+	}
+
+	source, err := readSource(name, stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "sho: %v\n", err)
+		return exitNoInput
+	}
 
-		let nothing = null
-		print(nothing)
-	`
+	return execute(opts, name, source, rest, stdout, stderr, false)
+}
 
-	l := lexer.New(input)
-	p := parser.New(l)
-	program := p.ParseProgram()
+func readSource(name string, stdin io.Reader) (string, error) {
+	if name == "-" {
+		data, err := io.ReadAll(stdin)
+		return string(data), err
+	}
 
-	if errors := p.Errors(); len(errors) != 0 {
-		for _, err := range errors {
-			fmt.Println("Parser error:", err)
+	data, err := os.ReadFile(name)
+	return string(data), err
+}
+
+func execute(opts []sho.Option, name, source string, args []string, stdout, stderr io.Writer, printResult bool) int {
+	interp := sho.New(append(opts, sho.WithFilename(name))...)
+	interp.SetGlobal("args", scriptArgs(args))
+
+	result, err := interp.Run(source)
+	if err != nil {
+		var syntaxErr *sho.SyntaxError
+		var runtimeErr *sho.RuntimeError
+
+		switch {
+		case errors.As(err, &syntaxErr):
+			for _, e := range syntaxErr.Errors {
+				fmt.Fprintf(stderr, "syntax error: %s\n", e.Render(name, source))
+			}
+			return exitSyntax
+		case errors.As(err, &runtimeErr):
+			fmt.Fprintln(stderr, runtimeErr.Traceback())
+			return exitRuntime
+		default:
+			fmt.Fprintf(stderr, "sho: %v\n", err)
+			return exitRuntime
 		}
-		return
 	}
 
-	env := run.NewEnvironment()
-	result := run.Eval(program, env)
-	if result != nil {
-		fmt.Println(result.Inspect())
+	if printResult && result != run.NULL {
+		fmt.Fprintln(stdout, result.Inspect())
+	}
+
+	return exitOK
+}
+
+func scriptArgs(args []string) *run.Array {
+	elements := make([]run.Object, len(args))
+	for i, arg := range args {
+		elements[i] = &run.String{Value: arg}
 	}
+	return &run.Array{Elements: elements}
 }
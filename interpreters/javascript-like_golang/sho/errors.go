@@ -0,0 +1,55 @@
+package sho
+
+import (
+	"fmt"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/parser"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/run"
+	"strings"
+)
+
+type SyntaxError struct {
+	Filename string
+	Source   string
+	Errors   []*parser.ParseError
+}
+
+func (e *SyntaxError) Error() string {
+	msg := prefix(e.Filename) + e.Errors[0].Error()
+	if len(e.Errors) > 1 {
+		msg += fmt.Sprintf(" (and %d more errors)", len(e.Errors)-1)
+	}
+	return msg
+}
+
+func (e *SyntaxError) Render() string {
+	rendered := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		rendered[i] = err.Render(e.Filename, e.Source)
+	}
+	return strings.Join(rendered, "\n\n")
+}
+
+type RuntimeError struct {
+	Filename string
+	Source   string
+	Err      *run.Error
+}
+
+func (e *RuntimeError) Error() string {
+	return prefix(e.Filename) + e.Err.Error()
+}
+
+func (e *RuntimeError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RuntimeError) Traceback() string {
+	return e.Err.Traceback(e.Filename, e.Source)
+}
+
+func prefix(filename string) string {
+	if filename == "" {
+		return ""
+	}
+	return filename + ":"
+}
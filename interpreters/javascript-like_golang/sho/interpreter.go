@@ -0,0 +1,211 @@
+package sho
+
+import (
+	"context"
+	"fmt"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/ast"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/lexer"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/parser"
+	"github.com/tyowk/simple-interpreter/interpreters/javascript-like_golang/run"
+	"io"
+	"os"
+	"time"
+)
+
+type Interpreter struct {
+	env      *run.Environment
+	runtime  *run.Runtime
+	filename string
+	timeout  time.Duration
+}
+
+type Option func(*Interpreter)
+
+func WithFilename(name string) Option {
+	return func(in *Interpreter) { in.filename = name }
+}
+
+func WithTimeout(d time.Duration) Option {
+	return func(in *Interpreter) { in.timeout = d }
+}
+
+func WithStdout(w io.Writer) Option {
+	return func(in *Interpreter) { in.runtime.Stdout = w }
+}
+
+func WithStderr(w io.Writer) Option {
+	return func(in *Interpreter) { in.runtime.Stderr = w }
+}
+
+func WithStdin(r io.Reader) Option {
+	return func(in *Interpreter) { in.runtime.Stdin = r }
+}
+
+func WithMaxCallDepth(depth int) Option {
+	return func(in *Interpreter) { in.runtime.MaxCallDepth = depth }
+}
+
+func WithMaxSteps(steps int64) Option {
+	return func(in *Interpreter) { in.runtime.MaxSteps = steps }
+}
+
+// WithMaxMemory caps how many bytes a run may add to strings, arrays, hashes
+// and big integers; see run.Runtime.MaxMemory for what is charged.
+func WithMaxMemory(bytes int64) Option {
+	return func(in *Interpreter) { in.runtime.MaxMemory = bytes }
+}
+
+func WithMaxStringLength(length int) Option {
+	return func(in *Interpreter) { in.runtime.MaxStringLength = length }
+}
+
+func WithMaxCollectionSize(size int) Option {
+	return func(in *Interpreter) { in.runtime.MaxCollectionSize = size }
+}
+
+func New(opts ...Option) *Interpreter {
+	rt := run.NewRuntime()
+	in := &Interpreter{
+		env:     run.NewEnvironmentWithRuntime(rt),
+		runtime: rt,
+	}
+
+	for _, opt := range opts {
+		opt(in)
+	}
+
+	return in
+}
+
+func (in *Interpreter) Runtime() *run.Runtime {
+	return in.runtime
+}
+
+func (in *Interpreter) Environment() *run.Environment {
+	return in.env
+}
+
+// Run evaluates src in the interpreter's global scope, which persists across
+// calls. Each call may let names that earlier calls or SetGlobal already
+// bound; declaring a name twice within src is still an error.
+func (in *Interpreter) Run(src string) (run.Object, error) {
+	return in.RunContext(context.Background(), src)
+}
+
+func (in *Interpreter) RunContext(ctx context.Context, src string) (run.Object, error) {
+	program, err := in.parse(in.filename, src)
+	if err != nil {
+		return nil, err
+	}
+	return in.eval(ctx, in.filename, src, program)
+}
+
+// RunProgram evaluates an already parsed program like RunContext. The
+// returned RuntimeError has no source, so callers that parsed the program
+// render its traceback from their own copy.
+func (in *Interpreter) RunProgram(ctx context.Context, program *ast.Program) (run.Object, error) {
+	return in.eval(ctx, in.filename, "", program)
+}
+
+func (in *Interpreter) RunFile(path string) (run.Object, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	src := string(data)
+	program, err := in.parse(path, src)
+	if err != nil {
+		return nil, err
+	}
+	return in.eval(context.Background(), path, src, program)
+}
+
+func (in *Interpreter) Eval(expr string) (run.Object, error) {
+	program, err := in.parse(in.filename, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(program.Statements) != 1 {
+		return nil, fmt.Errorf("sho: %q is not a single expression", expr)
+	}
+	if _, ok := program.Statements[0].(*ast.ExpressionStatement); !ok {
+		return nil, fmt.Errorf("sho: %q is not an expression", expr)
+	}
+
+	return in.eval(context.Background(), in.filename, expr, program)
+}
+
+func (in *Interpreter) SetGlobal(name string, value run.Object) {
+	in.env.Set(name, value)
+}
+
+func (in *Interpreter) GetGlobal(name string) (run.Object, bool) {
+	return in.env.Get(name)
+}
+
+func (in *Interpreter) SetGlobalValue(name string, value interface{}) error {
+	obj, err := run.ToObject(value)
+	if err != nil {
+		return fmt.Errorf("sho: set %s: %w", name, err)
+	}
+	in.env.Set(name, obj)
+	return nil
+}
+
+func (in *Interpreter) GetGlobalValue(name string, target interface{}) error {
+	obj, ok := in.env.Get(name)
+	if !ok {
+		return fmt.Errorf("sho: global %s is not defined", name)
+	}
+	return run.FromObject(obj, target)
+}
+
+func (in *Interpreter) RegisterFunc(name string, fn func(args ...run.Object) run.Object) {
+	in.runtime.Builtins().Register(name, &run.Builtin{Name: name, Fn: fn})
+}
+
+func (in *Interpreter) RegisterGoFunc(name string, fn interface{}) error {
+	builtin, err := run.WrapFunc(fn)
+	if err != nil {
+		return fmt.Errorf("sho: register %s: %w", name, err)
+	}
+	builtin.Name = name
+	in.runtime.Builtins().Register(name, builtin)
+	return nil
+}
+
+func (in *Interpreter) RemoveFunc(name string) {
+	in.runtime.Builtins().Remove(name)
+}
+
+func (in *Interpreter) parse(filename, src string) (*ast.Program, error) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+
+	if errors := p.ParseErrors(); len(errors) != 0 {
+		return nil, &SyntaxError{Filename: filename, Source: src, Errors: errors}
+	}
+
+	return program, nil
+}
+
+func (in *Interpreter) eval(ctx context.Context, filename, src string, program *ast.Program) (run.Object, error) {
+	if in.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, in.timeout)
+		defer cancel()
+	}
+
+	in.env.ResetDeclarations()
+	result := run.EvalContext(ctx, program, in.env)
+	if err, ok := result.(*run.Error); ok {
+		return nil, &RuntimeError{Filename: filename, Source: src, Err: err}
+	}
+	if result == nil {
+		return run.NULL, nil
+	}
+
+	return result, nil
+}
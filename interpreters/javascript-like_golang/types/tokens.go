@@ -1,5 +1,7 @@
 package types
 
+import "fmt"
+
 type TokenType int
 
 const (
@@ -7,6 +9,7 @@ const (
 	EOF
 	IDENT
 	INT
+	FLOAT
 	STRING
 	ASSIGN
 	PLUS
@@ -16,6 +19,35 @@ const (
 	SLASH
 	LT
 	GT
+	LT_EQ
+	GT_EQ
+	PERCENT
+	POWER
+	AMPERSAND
+	PIPE
+	CARET
+	TILDE
+	SHIFT_LEFT
+	SHIFT_RIGHT
+	AND
+	OR
+	NULLISH
+	PLUS_ASSIGN
+	MINUS_ASSIGN
+	ASTERISK_ASSIGN
+	SLASH_ASSIGN
+	PERCENT_ASSIGN
+	POWER_ASSIGN
+	AMPERSAND_ASSIGN
+	PIPE_ASSIGN
+	CARET_ASSIGN
+	SHIFT_LEFT_ASSIGN
+	SHIFT_RIGHT_ASSIGN
+	AND_ASSIGN
+	OR_ASSIGN
+	NULLISH_ASSIGN
+	INCREMENT
+	DECREMENT
 	COMMA
 	SEMICOLON
 	LPAREN
@@ -30,10 +62,13 @@ const (
 	FALSE
 	IF
 	ELSE
+	WHILE
+	FOR
+	BREAK
+	CONTINUE
 	RETURN
 	EQ
 	NOT_EQ
-	PRINT
 	CLASS
 	NEW
 	THIS
@@ -55,6 +90,8 @@ func (t TokenType) String() string {
 		return "IDENT"
 	case INT:
 		return "INT"
+	case FLOAT:
+		return "FLOAT"
 	case STRING:
 		return "STRING"
 	case ASSIGN:
@@ -73,6 +110,64 @@ func (t TokenType) String() string {
 		return "<"
 	case GT:
 		return ">"
+	case LT_EQ:
+		return "<="
+	case GT_EQ:
+		return ">="
+	case PERCENT:
+		return "%"
+	case POWER:
+		return "**"
+	case AMPERSAND:
+		return "&"
+	case PIPE:
+		return "|"
+	case CARET:
+		return "^"
+	case TILDE:
+		return "~"
+	case SHIFT_LEFT:
+		return "<<"
+	case SHIFT_RIGHT:
+		return ">>"
+	case AND:
+		return "&&"
+	case OR:
+		return "||"
+	case NULLISH:
+		return "??"
+	case PLUS_ASSIGN:
+		return "+="
+	case MINUS_ASSIGN:
+		return "-="
+	case ASTERISK_ASSIGN:
+		return "*="
+	case SLASH_ASSIGN:
+		return "/="
+	case PERCENT_ASSIGN:
+		return "%="
+	case POWER_ASSIGN:
+		return "**="
+	case AMPERSAND_ASSIGN:
+		return "&="
+	case PIPE_ASSIGN:
+		return "|="
+	case CARET_ASSIGN:
+		return "^="
+	case SHIFT_LEFT_ASSIGN:
+		return "<<="
+	case SHIFT_RIGHT_ASSIGN:
+		return ">>="
+	case AND_ASSIGN:
+		return "&&="
+	case OR_ASSIGN:
+		return "||="
+	case NULLISH_ASSIGN:
+		return "??="
+	case INCREMENT:
+		return "++"
+	case DECREMENT:
+		return "--"
 	case COMMA:
 		return ","
 	case SEMICOLON:
@@ -97,14 +192,20 @@ func (t TokenType) String() string {
 		return "IF"
 	case ELSE:
 		return "ELSE"
+	case WHILE:
+		return "WHILE"
+	case FOR:
+		return "FOR"
+	case BREAK:
+		return "BREAK"
+	case CONTINUE:
+		return "CONTINUE"
 	case RETURN:
 		return "RETURN"
 	case EQ:
 		return "=="
 	case NOT_EQ:
 		return "!="
-	case PRINT:
-		return "PRINT"
 	case LBRACKET:
 		return "["
 	case RBRACKET:
@@ -132,16 +233,33 @@ func (t TokenType) String() string {
 	}
 }
 
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+func (p Position) IsValid() bool { return p.Line > 0 }
+
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
 type Token struct {
-	Type     TokenType
-	Literal  string
-	Position int
+	Type    TokenType
+	Literal string
+	Start   Position
+	End     Position
 }
 
-func NewToken(tokenType TokenType, literal string, position int) Token {
+func NewToken(tokenType TokenType, literal string, start, end Position) Token {
 	return Token{
-		Type:     tokenType,
-		Literal:  literal,
-		Position: position,
+		Type:    tokenType,
+		Literal: literal,
+		Start:   start,
+		End:     end,
 	}
 }